@@ -0,0 +1,603 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/bundle"
+	"github.com/nraghuveer/vibecast/lib/captions"
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/doctor"
+	"github.com/nraghuveer/vibecast/lib/episode"
+	"github.com/nraghuveer/vibecast/lib/library"
+	"github.com/nraghuveer/vibecast/lib/llm"
+	"github.com/nraghuveer/vibecast/lib/publish"
+	"github.com/nraghuveer/vibecast/lib/retention"
+	"github.com/nraghuveer/vibecast/lib/script"
+	"github.com/nraghuveer/vibecast/lib/storage"
+	"github.com/nraghuveer/vibecast/lib/ttscache"
+)
+
+// command is a non-interactive subcommand run instead of the TUI.
+type command struct {
+	args    string
+	summary string
+	run     func(database *db.DB, args []string) error
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"render": {
+			args:    "<conversation-id>",
+			summary: "stitch a conversation's audio into episode.wav",
+			run:     runRender,
+		},
+		"captions": {
+			args:    "<conversation-id> [--align]",
+			summary: "write SRT and WebVTT captions timed to the rendered episode",
+			run:     runCaptions,
+		},
+		"publish": {
+			args:    "[dir]",
+			summary: "write ended conversations as a podcast directory with feed.xml",
+			run:     runPublish,
+		},
+		"export": {
+			args:    "<conversation-id> [file]",
+			summary: "write a conversation to a portable .vibecast bundle",
+			run:     runExport,
+		},
+		"script": {
+			args:    "<conversation-id> [file.md|html|txt]",
+			summary: "write a readable script, Markdown by default; --link-audio keeps HTML pages small",
+			run:     runScript,
+		},
+		"import": {
+			args:    "<file.vibecast>",
+			summary: "add a conversation from a .vibecast bundle",
+			run:     runImport,
+		},
+		"doctor": {
+			args:    "[--fix] [--dry-run]",
+			summary: "check the database and conversation folders agree; --fix repairs",
+			run:     runDoctor,
+		},
+		"cache": {
+			args:    "[inspect|clear]",
+			summary: "show or empty the synthesized speech cache",
+			run:     runCache,
+		},
+		"prune": {
+			args:    "[--dry-run] [--days N] [--max-mb N]",
+			summary: "delete old conversation clips per the retention settings; starred ones are kept",
+			run:     runPrune,
+		},
+		"encrypt": {
+			args:    "[--keyfile <path>]",
+			summary: "encrypt transcripts, clips and conversation details with a passphrase or keyfile",
+			run:     runEncrypt,
+		},
+		"decrypt": {
+			summary: "turn encryption off, writing everything back in the clear",
+			run:     runDecrypt,
+		},
+	}
+}
+
+// runCommand runs the subcommand named by args[0].
+func runCommand(database *db.DB, args []string) error {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], commandsUsage())
+	}
+	return cmd.run(database, args[1:])
+}
+
+func commandsUsage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("Commands:\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  vibecast %-36s %s\n", usageLine(name), commands[name].summary)
+	}
+	return b.String()
+}
+
+func usageLine(name string) string {
+	return strings.TrimSpace(name + " " + commands[name].args)
+}
+
+// parseInterspersed parses flags given before, between or after the
+// positional arguments, returning the positional ones.
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+func usageError(name string) error {
+	return errors.New("usage: vibecast " + usageLine(name))
+}
+
+func runRender(database *db.DB, args []string) error {
+	if len(args) != 1 {
+		return usageError("render")
+	}
+
+	result, err := episode.RenderConversation(context.Background(), database, args[0], episode.OptionsFromConfig())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rendered %d clips (%s)\n", result.Clips, result.Duration.Round(time.Second))
+	for _, path := range result.Files {
+		fmt.Printf("  %s\n", path)
+	}
+	for _, skipped := range result.Skipped {
+		fmt.Fprintf(os.Stderr, "  skipped %s\n", skipped)
+	}
+	return nil
+}
+
+func runCaptions(database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("captions", flag.ContinueOnError)
+	align := flags.Bool("align", false, "time captions by running speech-to-text on each clip")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil || len(positional) != 1 {
+		return usageError("captions")
+	}
+	conversationID := positional[0]
+
+	var aligner captions.Aligner
+	if *align {
+		client := llm.New()
+		provider := config.GetSpeechToTextProvider()
+		aligner = func(ctx context.Context, wavPath string) ([]captions.Span, error) {
+			transcription, err := client.TranscribeSpeech(ctx, provider, wavPath)
+			if err != nil {
+				return nil, err
+			}
+			spans := make([]captions.Span, 0, len(transcription.Segments))
+			for _, seg := range transcription.Segments {
+				spans = append(spans, captions.Span{Start: seg.Start, End: seg.End, Text: seg.Text})
+			}
+			return spans, nil
+		}
+	}
+
+	result, err := captions.ForConversation(context.Background(), database, conversationID, episode.OptionsFromConfig(), aligner)
+	if err != nil {
+		return err
+	}
+	conversationDir, err := storage.GetConversationDir(conversationID)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: couldn't align %s\n", warning)
+	}
+	fmt.Printf("Wrote %d captions for %d clips", len(result.Cues), result.Clips)
+	if *align {
+		fmt.Printf(", %d aligned by speech-to-text", result.Aligned)
+	}
+	fmt.Println()
+	for _, ext := range []string{".srt", ".vtt"} {
+		path := filepath.Join(conversationDir, episode.FileName+ext)
+		if err := captions.WriteFile(path, result.Cues); err != nil {
+			return err
+		}
+		fmt.Printf("  %s\n", path)
+	}
+	return nil
+}
+
+func runPublish(database *db.DB, args []string) error {
+	if len(args) > 1 {
+		return usageError("publish")
+	}
+	cfg := config.GetPublishConfig()
+	if len(args) == 1 {
+		cfg.Dir = args[0]
+	}
+
+	result, err := publish.Publish(context.Background(), database, cfg)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	for _, ep := range result.Episodes {
+		fmt.Printf("  #%d %s (%s)\n", ep.Number, ep.Title, time.Duration(ep.DurationSeconds)*time.Second)
+	}
+	for _, skipped := range result.Skipped {
+		fmt.Fprintf(os.Stderr, "  skipped %s\n", skipped)
+	}
+	fmt.Printf("Published %d episodes to %s\n", len(result.Episodes), result.Feed)
+	return nil
+}
+
+func runExport(database *db.DB, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return usageError("export")
+	}
+
+	c, err := database.GetConversation(args[0])
+	if err != nil {
+		return err
+	}
+	outPath := bundle.DefaultFileName(*c)
+	if len(args) == 2 {
+		outPath = config.ExpandPath(args[1])
+	}
+
+	result, err := bundle.Export(database, c.ID, outPath)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	fmt.Printf("Exported %q (%d messages, %d clips) to %s\n", c.Title, result.Messages, result.Clips, result.Path)
+	return nil
+}
+
+func runScript(database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("script", flag.ContinueOnError)
+	linkAudio := flags.Bool("link-audio", false, "link the HTML page to the clip files instead of embedding them")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil || len(positional) < 1 || len(positional) > 2 {
+		return usageError("script")
+	}
+
+	c, err := database.GetConversation(positional[0])
+	if err != nil {
+		return err
+	}
+	format := script.FormatMarkdown
+	outPath := script.DefaultFileName(*c, format)
+	if len(positional) == 2 {
+		outPath = config.ExpandPath(positional[1])
+		if format = script.FormatFor(outPath); format == "" {
+			return fmt.Errorf("unknown script format %q; use .%s", filepath.Ext(outPath), strings.Join(script.Formats, ", ."))
+		}
+	}
+
+	doc, err := script.Load(database, c.ID)
+	if err != nil {
+		return err
+	}
+	if err := script.WriteFile(doc, outPath, format, script.Options{LinkAudio: *linkAudio}); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %q (%d turns) to %s\n", c.Title, len(doc.Lines), outPath)
+	return nil
+}
+
+func runImport(database *db.DB, args []string) error {
+	if len(args) != 1 {
+		return usageError("import")
+	}
+
+	result, err := bundle.Import(database, config.ExpandPath(args[0]))
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	if result.Renamed {
+		fmt.Println("A conversation with this id already exists; imported as a copy with new ids")
+	}
+	fmt.Printf("Imported %q (%d messages, %d clips) as %s\n", result.Title, result.Messages, result.Clips, result.ConversationID)
+	return nil
+}
+
+func runDoctor(database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fix := flags.Bool("fix", false, "apply the suggested repairs")
+	dryRun := flags.Bool("dry-run", false, "show what --fix would do without changing anything")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return usageError("doctor")
+	}
+
+	report, err := doctor.Check(database)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Data directory: %s\n", report.DataDir)
+	fmt.Printf("Database: %s\n", report.DBPath)
+	fmt.Printf("Checked %d conversations\n", report.Conversations)
+	if len(report.Issues) == 0 {
+		fmt.Println("No problems found")
+		return nil
+	}
+
+	fmt.Println()
+	for _, issue := range report.Issues {
+		fmt.Printf("%-8s %s\n", issue.Severity, issue.Problem)
+		if issue.ConversationID != "" {
+			fmt.Printf("         conversation %s\n", issue.ConversationID)
+		}
+		if issue.Path != "" {
+			fmt.Printf("         %s\n", issue.Path)
+		}
+		if issue.Repair != "" && !*fix && !*dryRun {
+			fmt.Printf("         repair (%s): %s\n", issue.Repair, issue.RepairDetail)
+		}
+	}
+	fmt.Println()
+
+	repairable := report.Repairable()
+	summary := fmt.Sprintf("%d errors, %d warnings, %d repairable", report.Count(doctor.Error), report.Count(doctor.Warning), len(repairable))
+	switch {
+	case *dryRun:
+		fmt.Println(summary)
+		for _, issue := range repairable {
+			fmt.Printf("  would %s: %s\n", issue.Repair, issue.RepairDetail)
+		}
+	case *fix:
+		fmt.Println(summary)
+		failed := 0
+		for _, issue := range repairable {
+			if err := issue.Fix(); err != nil {
+				failed++
+				fmt.Fprintf(os.Stderr, "  failed to %s %s: %v\n", issue.Repair, issue.Path, err)
+				continue
+			}
+			fmt.Printf("  %s: %s\n", issue.Repair, issue.RepairDetail)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d repairs failed", failed)
+		}
+	default:
+		if len(repairable) > 0 {
+			summary += "; run vibecast doctor --fix to repair (--dry-run to preview)"
+		}
+		fmt.Println(summary)
+	}
+	return nil
+}
+
+// cacheListLimit is how many entries cache inspect prints.
+const cacheListLimit = 10
+
+func runCache(database *db.DB, args []string) error {
+	action := "inspect"
+	if len(args) == 1 {
+		action = args[0]
+	}
+	if len(args) > 1 || (action != "inspect" && action != "clear") {
+		return usageError("cache")
+	}
+
+	if action == "clear" {
+		removed, err := ttscache.Clear()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d cached clips\n", removed)
+		return nil
+	}
+
+	stats, err := ttscache.Inspect()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("TTS cache: %s\n", stats.Dir)
+	if !config.GetCacheConfig().TTSEnabled() {
+		fmt.Println("  disabled (cache.disable_tts)")
+	}
+	fmt.Printf("  %d clips, %s of %s\n", stats.Entries, formatBytes(stats.Bytes), formatBytes(stats.MaxBytes))
+
+	entries, err := ttscache.List()
+	if err != nil {
+		return err
+	}
+	for i, e := range entries {
+		if i == cacheListLimit {
+			fmt.Printf("  ...and %d more\n", len(entries)-i)
+			break
+		}
+		fmt.Printf("  %s  %8s  %s/%s  %q\n",
+			e.LastUsed.Format("2006-01-02 15:04"),
+			formatBytes(e.Size),
+			e.Key.Provider,
+			e.Key.Voice,
+			truncateText(e.Key.Text, 48),
+		)
+	}
+	return nil
+}
+
+func runPrune(database *db.DB, args []string) error {
+	cfg := config.GetRetentionConfig()
+	flags := flag.NewFlagSet("prune", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "show what would be pruned without deleting anything")
+	days := flags.Int("days", cfg.AudioDays, "prune clips older than this many days")
+	maxMB := flags.Int("max-mb", cfg.MaxAudioMB, "prune the oldest clips until all fit in this many MB")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 || *days < 0 || *maxMB < 0 {
+		return usageError("prune")
+	}
+	cfg.AudioDays, cfg.MaxAudioMB = *days, *maxMB
+
+	policy := retention.Policy{MaxAge: cfg.MaxAge(), MaxBytes: cfg.MaxBytes()}
+	if !policy.Enabled() {
+		return errors.New("no retention limit set; set retention.audio_days or retention.max_audio_mb, or pass --days or --max-mb")
+	}
+
+	report, err := retention.Run(database, policy, *dryRun)
+	printPruneReport(report, *dryRun, cfg)
+	return err
+}
+
+func printPruneReport(report retention.Report, dryRun bool, cfg config.RetentionConfig) {
+	verb, reclaim := "Pruned", "reclaimed"
+	if dryRun {
+		verb, reclaim = "Would prune", "reclaiming"
+	}
+	if len(report.Pruned) == 0 {
+		fmt.Printf("Nothing to prune; %s of clips kept\n", formatBytes(report.Remaining))
+	} else {
+		fmt.Printf("%s %d clips, %s %s; %s of clips kept\n",
+			verb, report.Clips, reclaim, formatBytes(report.Reclaimed), formatBytes(report.Remaining))
+		for _, p := range report.Pruned {
+			reason := fmt.Sprintf("over %d days old", cfg.AudioDays)
+			if p.Reason == retention.ReasonQuota {
+				reason = fmt.Sprintf("over the %d MB quota", cfg.MaxAudioMB)
+			}
+			fmt.Printf("  %s  %3d clips  %8s  %3d kept  %q  %s\n",
+				p.Newest.Format("2006-01-02"), p.Clips, formatBytes(p.Bytes), p.Kept, truncateText(p.Title, 40), reason)
+		}
+	}
+	if report.Starred > 0 {
+		fmt.Printf("%d starred conversations left alone\n", report.Starred)
+	}
+	if cfg.MaxAudioMB > 0 && report.Remaining > cfg.MaxBytes() {
+		fmt.Printf("Clips still exceed the %d MB quota; starred conversations are never pruned\n", cfg.MaxAudioMB)
+	}
+}
+
+func runEncrypt(database *db.DB, args []string) error {
+	flags := flag.NewFlagSet("encrypt", flag.ContinueOnError)
+	keyfile := flags.String("keyfile", "", "derive the key from this file, creating it if missing")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return usageError("encrypt")
+	}
+
+	dataDir := config.GetDataDir()
+	if crypt.Enabled() {
+		if *keyfile != "" {
+			return errors.New("data is already encrypted; run vibecast decrypt first to change the key")
+		}
+		fmt.Println("Data is already encrypted; sealing anything left in the clear")
+	} else {
+		params, key, err := newEncryption(*keyfile)
+		if err != nil {
+			return err
+		}
+		if err := crypt.SaveParams(dataDir, params); err != nil {
+			return err
+		}
+		crypt.SetKey(key)
+		fmt.Printf("Encrypting %s\n", dataDir)
+	}
+
+	result, err := library.Reseal(database, true)
+	printReseal("Encrypted", result)
+	return err
+}
+
+// newEncryption makes the parameters and key for a passphrase, or for
+// keyfile if one is given, creating the keyfile when it doesn't exist.
+func newEncryption(keyfile string) (crypt.Params, *crypt.Key, error) {
+	var params crypt.Params
+	var secret []byte
+	var err error
+	if keyfile == "" {
+		if secret, err = readPassphrase("New passphrase: ", true); err != nil {
+			return params, nil, err
+		}
+		params, err = crypt.NewParams(crypt.KDFPassphrase, "")
+	} else {
+		path, absErr := filepath.Abs(config.ExpandPath(keyfile))
+		if absErr != nil {
+			return params, nil, fmt.Errorf("failed to resolve keyfile path: %w", absErr)
+		}
+		if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+			if err := crypt.NewKeyfile(path); err != nil {
+				return params, nil, err
+			}
+			fmt.Printf("Created keyfile %s; keep a copy somewhere safe\n", path)
+		}
+		if secret, err = readKeyfile(path); err != nil {
+			return params, nil, err
+		}
+		params, err = crypt.NewParams(crypt.KDFKeyfile, path)
+	}
+	if err != nil {
+		return params, nil, err
+	}
+
+	key, err := params.Derive(secret)
+	if err != nil {
+		return params, nil, err
+	}
+	params.SetCheck(key)
+	return params, key, nil
+}
+
+func runDecrypt(database *db.DB, args []string) error {
+	if len(args) > 0 {
+		return usageError("decrypt")
+	}
+	if !crypt.Enabled() {
+		return errors.New("data is not encrypted")
+	}
+
+	result, err := library.Reseal(database, false)
+	printReseal("Decrypted", result)
+	if err != nil {
+		return err
+	}
+	if err := crypt.RemoveParams(config.GetDataDir()); err != nil {
+		return err
+	}
+	crypt.SetKey(nil)
+	fmt.Println("Encryption is off")
+	return nil
+}
+
+func printReseal(verb string, result library.ResealResult) {
+	fmt.Printf("%s %d transcripts, %d clips, %d rendered files, %d cache files and %d database values\n",
+		verb, result.Transcripts, result.Clips, result.Rendered, result.Cached, result.Values)
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+func truncateText(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
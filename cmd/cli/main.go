@@ -4,23 +4,35 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/nraghuveer/vibecast/lib/config"
 	"github.com/nraghuveer/vibecast/lib/data"
 	"github.com/nraghuveer/vibecast/lib/db"
 	"github.com/nraghuveer/vibecast/lib/logger"
+	"github.com/nraghuveer/vibecast/lib/retention"
+	"github.com/nraghuveer/vibecast/lib/storage"
+	"github.com/nraghuveer/vibecast/lib/voices"
 )
 
 func main() {
+	configPath := flag.String("config", "", "Path to config file (default: $VIBECAST_HOME/config.yml or ~/.vibecast/config.yml)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: vibecast [flags] [command]\n\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(flag.CommandLine.Output(), "\n%s", commandsUsage())
+	}
+	flag.Parse()
+
+	// Load config before the logger so logs follow data_dir
+	_, configErr := config.Load(*configPath)
+
 	// Initialize logger
 	log := logger.GetInstance()
 	defer log.Close()
 
-	configPath := flag.String("config", "", "Path to config file (default: ~/.vibecast/config.yml)")
-	flag.Parse()
-
-	if _, err := config.Load(*configPath); err != nil {
+	if err := configErr; err != nil {
 		log.LogError("config_load", err)
 		fmt.Printf("Error loading config: %v\n", err)
 		os.Exit(1)
@@ -28,13 +40,13 @@ func main() {
 
 	log.Info("config_loaded", "path", config.GetConfigPath())
 
-	fmt.Printf("Using config: %s\n", config.GetConfigPath())
-	fmt.Printf("Database: %s\n", config.GetDBPath())
-	fmt.Printf("Conversation Provider: %s\n", config.GetConversationProvider())
-	fmt.Printf("Speech to Text Provider: %s\n", config.GetSpeechToTextProvider())
-	fmt.Printf("Text to Speech Provider: %s\n", config.GetTextToSpeechProvider())
+	log.Info("app_init", "config_path", config.GetConfigPath(), "data_dir", config.GetDataDir(), "db_path", config.GetDBPath())
 
-	log.Info("app_init", "config_path", config.GetConfigPath(), "db_path", config.GetDBPath())
+	if err := unlock(); err != nil {
+		log.LogError("unlock", err)
+		fmt.Printf("Error unlocking data: %v\n", err)
+		os.Exit(1)
+	}
 
 	database, err := db.NewDB()
 	if err != nil {
@@ -46,6 +58,45 @@ func main() {
 	log.Info("database_initialized")
 
 	data.InitializeDefaultTemplates(database)
+	voices.Sync(database)
+
+	if args := flag.Args(); len(args) > 0 {
+		if err := runCommand(database, args); err != nil {
+			log.LogError("command_"+args[0], err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Upgrades run only when the app starts, so commands such as doctor
+	// see the data as it is.
+	if migrated, err := storage.MigrateTranscripts(); err != nil {
+		log.LogError("transcript_migration", err)
+	} else if migrated > 0 {
+		log.Info("transcripts_migrated", "count", migrated)
+	}
+	if err := backfillMessageIndex(database, log); err != nil {
+		log.LogError("message_index_backfill", err)
+	}
+
+	if policy := retention.ConfiguredPolicy(); policy.Enabled() && !config.GetRetentionConfig().DisableOnStart {
+		report, err := retention.Run(database, policy, false)
+		if err != nil {
+			log.LogError("audio_retention", err)
+		}
+		if report.Clips > 0 {
+			log.Info("audio_pruned", "clips", report.Clips, "bytes", report.Reclaimed)
+			fmt.Printf("Pruned %d old clips, reclaimed %s\n", report.Clips, formatBytes(report.Reclaimed))
+		}
+	}
+
+	fmt.Printf("Using config: %s\n", config.GetConfigPath())
+	fmt.Printf("Data directory: %s\n", config.GetDataDir())
+	fmt.Printf("Database: %s\n", config.GetDBPath())
+	fmt.Printf("Conversation Provider: %s\n", config.GetConversationProvider())
+	fmt.Printf("Speech to Text Provider: %s\n", config.GetSpeechToTextProvider())
+	fmt.Printf("Text to Speech Provider: %s\n", config.GetTextToSpeechProvider())
 
 	p := tea.NewProgram(
 		NewModel(database),
@@ -60,3 +111,33 @@ func main() {
 
 	log.Info("program_exited_normally")
 }
+
+// backfillMessageIndex copies transcripts written before the search index
+// existed into it. It runs on every start until one pass indexes every
+// conversation; after that the index is kept up to date as messages are
+// written, and doctor reindexes anything that falls behind.
+func backfillMessageIndex(database *db.DB, log *logger.Logger) error {
+	done, err := database.GetMeta(db.MetaMessagesIndexed)
+	if err != nil || done != "" {
+		return err
+	}
+
+	conversations, err := database.GetAllConversations()
+	if err != nil {
+		return err
+	}
+	complete := true
+	for _, c := range conversations {
+		indexed, err := storage.IndexMessages(database, c.ID)
+		if err != nil {
+			log.LogError("message_index_backfill", err)
+			complete = false
+		} else if indexed > 0 {
+			log.Info("messages_indexed", "conversation_id", c.ID, "count", indexed)
+		}
+	}
+	if !complete {
+		return nil
+	}
+	return database.SetMeta(db.MetaMessagesIndexed, time.Now().UTC().Format(time.RFC3339))
+}
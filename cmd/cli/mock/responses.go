@@ -82,22 +82,3 @@ func GetResponse(input string, isFirstMessage bool) string {
 	// Return generic response
 	return genericResponses[rand.Intn(len(genericResponses))]
 }
-
-// Voice represents a mock voice option
-type Voice struct {
-	ID          string
-	Name        string
-	Description string
-}
-
-// GetVoices returns the list of available mock voices
-func GetVoices() []Voice {
-	return []Voice{
-		{ID: "alloy", Name: "Alloy", Description: "neutral"},
-		{ID: "echo", Name: "Echo", Description: "male"},
-		{ID: "fable", Name: "Fable", Description: "British"},
-		{ID: "onyx", Name: "Onyx", Description: "deep male"},
-		{ID: "nova", Name: "Nova", Description: "female"},
-		{ID: "shimmer", Name: "Shimmer", Description: "soft female"},
-	}
-}
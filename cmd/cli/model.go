@@ -2,7 +2,6 @@ package main
 
 import (
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/nraghuveer/vibecast/cmd/cli/mock"
 	"github.com/nraghuveer/vibecast/cmd/cli/screens"
 	"github.com/nraghuveer/vibecast/lib/data"
 	"github.com/nraghuveer/vibecast/lib/db"
@@ -19,6 +18,7 @@ const (
 	ScreenTopic
 	ScreenPersona
 	ScreenVoice
+	ScreenHostVoice
 	ScreenProvider
 	ScreenConversation
 	ScreenPreset
@@ -43,11 +43,12 @@ type Model struct {
 	templateName     screens.TemplateNameModel
 
 	// Collected data
-	selectedTitle    string
-	selectedTopic    string
-	selectedPersona  string
-	selectedVoice    mock.Voice
-	selectedProvider string
+	selectedTitle     string
+	selectedTopic     string
+	selectedPersona   string
+	selectedVoice     models.VoiceProfile
+	selectedHostVoice models.VoiceProfile
+	selectedProvider  string
 
 	// Template creation data
 	newTemplateName string
@@ -64,7 +65,7 @@ func NewModel(database *db.DB) Model {
 		welcome:      screens.NewWelcomeModel(),
 		topic:        screens.NewTopicModel(),
 		persona:      screens.NewPersonaModel(),
-		voice:        screens.NewVoiceModel(),
+		voice:        screens.NewVoiceModel(database),
 		provider:     screens.NewProviderModel(),
 		preset:       screens.NewPresetModel(database),
 		templateName: screens.NewTemplateNameModel(),
@@ -97,6 +98,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updatePersona(msg)
 	case ScreenVoice:
 		return m.updateVoice(msg)
+	case ScreenHostVoice:
+		return m.updateHostVoice(msg)
 	case ScreenProvider:
 		return m.updateProvider(msg)
 	case ScreenConversation:
@@ -154,7 +157,7 @@ func (m Model) updateNewConversation(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.selectedPersona = ncm.Persona
 		m.selectedProvider = ncm.Provider
 		m.screen = ScreenVoice
-		m.voice = screens.NewVoiceModel()
+		m.voice = screens.NewVoiceModel(m.db)
 		return m, m.voice.Init()
 	}
 
@@ -182,6 +185,7 @@ func (m Model) updateConversationList(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.width,
 			m.height,
 		)
+		m.conversation = m.conversation.FocusMessage(csm.MessageID)
 		return m, m.conversation.Init()
 	}
 
@@ -227,9 +231,23 @@ func (m Model) updateVoice(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	m.voice, cmd = m.voice.Update(msg)
 
-	// Check for voice selection
+	// Check for voice selection, then pick the host's voice
 	if vsm, ok := msg.(screens.VoiceSelectedMsg); ok {
 		m.selectedVoice = vsm.Voice
+		m.screen = ScreenHostVoice
+		m.voice = screens.NewHostVoiceModel(m.db)
+		return m, m.voice.Init()
+	}
+
+	return m, cmd
+}
+
+func (m Model) updateHostVoice(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.voice, cmd = m.voice.Update(msg)
+
+	if hvm, ok := msg.(screens.HostVoiceSelectedMsg); ok {
+		m.selectedHostVoice = hvm.Voice
 
 		// If provider is already selected (from NewConversation screen), go directly to conversation
 		if m.selectedProvider != "" {
@@ -240,6 +258,7 @@ func (m Model) updateVoice(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.selectedTopic,
 				m.selectedPersona,
 				m.selectedVoice,
+				m.selectedHostVoice,
 				m.selectedProvider,
 				m.width,
 				m.height,
@@ -269,6 +288,7 @@ func (m Model) updateProvider(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.selectedTopic,
 			m.selectedPersona,
 			m.selectedVoice,
+			m.selectedHostVoice,
 			m.selectedProvider,
 			m.width,
 			m.height,
@@ -282,6 +302,22 @@ func (m Model) updateProvider(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) updateConversation(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	m.conversation, cmd = m.conversation.Update(msg)
+
+	// Continue in the fork, at the message it was forked from
+	if cfm, ok := msg.(screens.ConversationForkedMsg); ok {
+		m.conversation = screens.NewConversationModelFromExisting(
+			m.db,
+			cfm.Conversation,
+			m.width,
+			m.height,
+		)
+		messages := m.conversation.Messages()
+		if len(messages) > 0 {
+			m.conversation = m.conversation.FocusMessage(messages[len(messages)-1].ID)
+		}
+		return m, m.conversation.Init()
+	}
+
 	return m, cmd
 }
 
@@ -295,7 +331,7 @@ func (m Model) updatePreset(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.selectedTopic = psm.Template.Topic
 		m.selectedPersona = psm.Template.Persona
 		m.screen = ScreenVoice
-		m.voice = screens.NewVoiceModel()
+		m.voice = screens.NewVoiceModel(m.db)
 		return m, m.voice.Init()
 	}
 
@@ -387,7 +423,7 @@ func (m Model) View() string {
 		return m.topic.View()
 	case ScreenPersona, ScreenTemplatePersona:
 		return m.persona.View()
-	case ScreenVoice:
+	case ScreenVoice, ScreenHostVoice:
 		return m.voice.View()
 	case ScreenProvider:
 		return m.provider.View()
@@ -3,6 +3,7 @@ package screens
 import (
 	"context"
 	"fmt"
+	"math"
 	"path/filepath"
 	"strings"
 	"time"
@@ -12,22 +13,34 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/google/uuid"
-	"github.com/nraghuveer/vibecast/cmd/cli/mock"
 	"github.com/nraghuveer/vibecast/cmd/cli/styles"
 	"github.com/nraghuveer/vibecast/lib/audio"
 	"github.com/nraghuveer/vibecast/lib/config"
 	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/library"
 	"github.com/nraghuveer/vibecast/lib/llm"
 	"github.com/nraghuveer/vibecast/lib/logger"
 	"github.com/nraghuveer/vibecast/lib/models"
 	"github.com/nraghuveer/vibecast/lib/storage"
+	"github.com/nraghuveer/vibecast/lib/voices"
 )
 
 // Message represents a chat message
 type Message struct {
-	Content  string
-	Speaker  models.SpeakerType
-	Complete bool
+	ID        string // transcript record id; empty until saved
+	Content   string
+	Speaker   models.SpeakerType
+	Complete  bool
+	TakeOf    string // first take's id when this is a retake
+	Alternate bool   // a take that isn't the canonical one
+}
+
+// takeGroup returns the id shared by all takes of the message's answer.
+func (msg Message) takeGroup() string {
+	if msg.TakeOf != "" {
+		return msg.TakeOf
+	}
+	return msg.ID
 }
 
 // ConversationModel represents the main conversation screen
@@ -40,13 +53,17 @@ type ConversationModel struct {
 	title         string
 	topic         string
 	persona       string
-	voice         mock.Voice
+	voice         models.VoiceProfile
+	hostVoice     models.VoiceProfile
+	voiceHost     bool // synthesize the host's lines with hostVoice
 	provider      string
 	isTyping      bool
 	streamingText string
-	ttsQueue      []string
-	ttsInFlight   bool
-	ttsLastChunk  string
+	ttsQueue      []ttsItem
+	ttsJobs       []*ttsJob // started syntheses, in playback order
+	ttsSeq        int
+	ttsSaving     bool // the head job is being saved
+	ttsLastChunk  ttsItem
 	llmRawBuffer  string
 	llmInSpeech   bool
 	llmSpeechBuf  string
@@ -61,10 +78,27 @@ type ConversationModel struct {
 	sttDraft      string
 	logger        *logger.Logger
 	toastModel    ToastModel
+	wave          WaveModel
+	showWave      bool
+	audioPlaying  bool
+	answerClips   int // clips queued for the latest guest answer, for replay
+	answerStarted time.Time
+	firstTokenAt  time.Time
+	focusID       string               // message scrolled into view, e.g. from search
+	answerID      string               // transcript id of the guest answer being streamed
+	messageAudio  map[string][]string  // message id -> clip paths, in order
+	selecting     bool                 // picking a past message to replay or fork from (Ctrl+O)
+	parentTitle   string               // title of the conversation this was forked from
+	takes         map[string][]Message // take group -> every take, for answers with retakes
+	retakeOf      string               // take group of the answer being regenerated
+	retakePrev    Message              // take shown before the retake, restored if it fails
+	editingID     string               // host message being edited in the input
+	confirmUndo   bool                 // asking before removing the last exchange (Ctrl+Z)
 }
 
-// NewConversationModelWithTitle creates a new conversation screen model with a title
-func NewConversationModelWithTitle(database *db.DB, title, topic, persona string, voice mock.Voice, provider string, width, height int) ConversationModel {
+// NewConversationModelWithTitle creates a new conversation screen model with a title.
+// A hostVoice with an empty ID leaves the host's lines unvoiced.
+func NewConversationModelWithTitle(database *db.DB, title, topic, persona string, voice, hostVoice models.VoiceProfile, provider string, width, height int) ConversationModel {
 	ti := textinput.New()
 	ti.Placeholder = "Type your message..."
 	ti.Focus()
@@ -81,38 +115,49 @@ func NewConversationModelWithTitle(database *db.DB, title, topic, persona string
 	}
 
 	conv := models.Conversation{
-		ID:        conversationID,
-		Title:     title,
-		Topic:     topic,
-		Persona:   persona,
-		VoiceID:   voice.ID,
-		VoiceName: voice.Name,
-		Provider:  provider,
-		CreatedAt: time.Now(),
+		ID:             conversationID,
+		Title:          title,
+		Topic:          topic,
+		Persona:        persona,
+		VoiceID:        voice.VoiceID,
+		VoiceName:      voice.Name,
+		VoiceProfileID: voice.ID,
+		Provider:       provider,
+		CreatedAt:      time.Now(),
+	}
+	conv.HostVoiceProfileID = hostVoice.ID
+	if hostVoice.ID == "" {
+		conv.HostVoiceProfileID = voices.HostVoiceNone
 	}
 	database.CreateConversation(conv)
 
 	return ConversationModel{
-		db:          database,
-		textInput:   ti,
-		messages:    []Message{},
-		width:       width,
-		height:      height,
-		title:       title,
-		topic:       topic,
-		persona:     persona,
-		voice:       voice,
-		provider:    provider,
-		id:          conversationID,
-		dotFrame:    0,
-		showDetails: false,
-		inputMode:   "text",
-		isMuted:     false,
-		sttDraft:    "",
-		ttsQueue:    []string{},
-		llmClient:   llm.New(),
-		logger:      logger.GetInstance(),
-		toastModel:  NewToastModel(),
+		db:           database,
+		textInput:    ti,
+		messages:     []Message{},
+		width:        width,
+		height:       height,
+		title:        title,
+		topic:        topic,
+		persona:      persona,
+		voice:        voice,
+		hostVoice:    hostVoice,
+		voiceHost:    hostVoice.ID != "",
+		provider:     provider,
+		id:           conversationID,
+		dotFrame:     0,
+		showDetails:  false,
+		inputMode:    "text",
+		isMuted:      false,
+		sttDraft:     "",
+		ttsQueue:     []ttsItem{},
+		llmClient:    llm.New(),
+		logger:       logger.GetInstance(),
+		toastModel:   NewToastModel(),
+		wave:         newConversationWave(),
+		showWave:     audio.BackendName() != "",
+		messageAudio: map[string][]string{},
+		takes:        map[string][]Message{},
 	}
 }
 
@@ -129,40 +174,116 @@ func NewConversationModelFromExisting(database *db.DB, conversation db.Conversat
 		loadedMessages = []storage.Message{}
 	}
 
-	// Convert storage messages to screen messages
+	// Clips saved before audio was indexed in the database
+	if count, err := database.CountAudioFiles(conversation.ID); err == nil && count == 0 {
+		if _, err := storage.IndexAudioDir(database, conversation.ID); err != nil {
+			logger.GetInstance().LogError("audio_index_backfill", err)
+		}
+	}
+
+	hostVoice, voiceHost := voices.HostForConversation(database, conversation)
+
+	var parentTitle string
+	if conversation.ParentID != "" {
+		if parent, err := database.GetConversation(conversation.ParentID); err == nil {
+			parentTitle = parent.Title
+		}
+	}
+
+	// Convert storage messages to screen messages, showing the canonical
+	// take of each answer
 	var messages []Message
+	takes := map[string][]Message{}
 	for _, msg := range loadedMessages {
-		messages = append(messages, Message{
-			Content:  msg.Content,
-			Speaker:  msg.Speaker,
-			Complete: true,
-		})
+		message := Message{
+			ID:        msg.ID,
+			Content:   msg.Content,
+			Speaker:   msg.Speaker,
+			Complete:  true,
+			TakeOf:    msg.TakeOf,
+			Alternate: msg.Alternate,
+		}
+		if !msg.Alternate {
+			messages = append(messages, message)
+		}
+		takes[msg.TakeGroup()] = append(takes[msg.TakeGroup()], message)
+	}
+	for group, groupTakes := range takes {
+		if len(groupTakes) < 2 {
+			delete(takes, group)
+		}
 	}
 
 	return ConversationModel{
-		db:          database,
-		textInput:   ti,
-		messages:    messages,
-		width:       width,
-		height:      height,
-		title:       conversation.Title,
-		topic:       conversation.Topic,
-		persona:     conversation.Persona,
-		voice:       mock.Voice{ID: conversation.VoiceID, Name: conversation.VoiceName},
-		provider:    conversation.Provider,
-		id:          conversation.ID,
-		dotFrame:    0,
-		showDetails: false,
-		inputMode:   "text",
-		isMuted:     false,
-		sttDraft:    "",
-		ttsQueue:    []string{},
-		llmClient:   llm.New(),
-		logger:      logger.GetInstance(),
-		toastModel:  NewToastModel(),
+		db:           database,
+		textInput:    ti,
+		messages:     messages,
+		width:        width,
+		height:       height,
+		title:        conversation.Title,
+		topic:        conversation.Topic,
+		persona:      conversation.Persona,
+		voice:        voices.ForConversation(database, conversation),
+		hostVoice:    hostVoice,
+		voiceHost:    voiceHost,
+		provider:     conversation.Provider,
+		id:           conversation.ID,
+		dotFrame:     0,
+		showDetails:  false,
+		inputMode:    "text",
+		isMuted:      false,
+		sttDraft:     "",
+		ttsQueue:     []ttsItem{},
+		llmClient:    llm.New(),
+		logger:       logger.GetInstance(),
+		toastModel:   NewToastModel(),
+		wave:         newConversationWave(),
+		showWave:     audio.BackendName() != "",
+		messageAudio: loadMessageAudio(database, conversation.ID, loadedMessages),
+		parentTitle:  parentTitle,
+		takes:        takes,
 	}
 }
 
+// loadMessageAudio maps each message to its clips, linking clips saved
+// before clips were linked to messages.
+func loadMessageAudio(database *db.DB, conversationID string, messages []storage.Message) map[string][]string {
+	log := logger.GetInstance()
+	messageAudio := map[string][]string{}
+
+	files, err := database.GetAudioFiles(conversationID)
+	if err != nil {
+		log.LogError("audio_files_load", err)
+		return messageAudio
+	}
+	audioDir, err := storage.GetAudioDir(conversationID)
+	if err != nil {
+		log.LogError("audio_files_load", err)
+		return messageAudio
+	}
+
+	links := storage.InferAudioLinks(messages, files)
+	for _, f := range files {
+		messageID := f.MessageID
+		if messageID == "" {
+			messageID = links[f.Filename]
+			if messageID == "" {
+				continue
+			}
+			if err := database.LinkAudioFile(conversationID, f.Filename, messageID); err != nil {
+				log.LogError("audio_link_backfill", err)
+			}
+		}
+		messageAudio[messageID] = append(messageAudio[messageID], filepath.Join(audioDir, f.Filename))
+	}
+	return messageAudio
+}
+
+func newConversationWave() WaveModel {
+	wc := config.GetUIConfig().Wave
+	return NewWaveModel(wc.Phase, wc.Frequency, float64(wc.Amplitude))
+}
+
 // Init initializes the conversation model
 func (m ConversationModel) Init() tea.Cmd {
 	// Check last message to determine whose turn it is
@@ -173,6 +294,7 @@ func (m ConversationModel) Init() tea.Cmd {
 		// New conversation: Guest starts with greeting
 		return tea.Batch(
 			textinput.Blink,
+			waitAudioLevelCmd(),
 			m.startGuestResponse(true),
 		)
 	}
@@ -183,12 +305,13 @@ func (m ConversationModel) Init() tea.Cmd {
 		// Host spoke last, it's Guest's turn to respond
 		return tea.Batch(
 			textinput.Blink,
+			waitAudioLevelCmd(),
 			m.startGuestResponse(false),
 		)
 	}
 
 	// Guest spoke last, wait for Host input
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, waitAudioLevelCmd())
 }
 
 // DotAnimationMsg is sent for flowing dots animation
@@ -204,9 +327,16 @@ type LLMStreamMsg struct {
 
 // TTSSavedMsg indicates synthesized audio has been saved.
 type TTSSavedMsg struct {
-	Filename string
-	Path     string
-	Err      error
+	Filename  string
+	Path      string
+	Speaker   models.SpeakerType
+	MessageID string
+	Err       error
+}
+
+// AudioLevelMsg delivers a loudness reading for the clip being played.
+type AudioLevelMsg struct {
+	Level audio.Level
 }
 
 // SttDraftMsg updates the live speech-to-text draft text.
@@ -247,9 +377,13 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 		m.streamingText = ""
 		m.dotFrame = 0
 		m.resetLLMParser()
-		m.ttsQueue = nil
-		m.ttsInFlight = false
-		m.ttsLastChunk = ""
+		// Drop guest blocks from an earlier answer, but keep host lines
+		// still waiting to be voiced.
+		m = m.cancelGuestTTS()
+		m.answerClips = 0
+		m.answerStarted = time.Now()
+		m.firstTokenAt = time.Time{}
+		m.answerID = uuid.New().String()
 
 		ctx, cancel := context.WithCancel(context.Background())
 		m.llmCancel = cancel
@@ -259,10 +393,12 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 		if err != nil {
 			m.isTyping = false
 			m.logger.LogError("llm_stream_init", err)
+			if m.retakeOf != "" {
+				return m.failRetake("")
+			}
 			m.toastModel.AddError("AI connection failed. Check your settings.")
 			notice := "Sorry—I'm having trouble connecting to the AI provider right now. Give me a moment and try again."
-			m.messages = append(m.messages, Message{Content: notice, Speaker: models.GUEST, Complete: true})
-			_ = storage.AppendMessage(m.id, "Guest", notice)
+			m = m.recordMessage(storage.Message{Speaker: models.GUEST, Content: notice})
 			return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 5*time.Second)
 		}
 
@@ -278,16 +414,32 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 				m.llmCancel = nil
 			}
 			m.resetLLMParser()
-
 			m.logger.LogError("llm_stream_error", msg.Event.Err)
+
+			if m.retakeOf != "" {
+				partial := strings.TrimSpace(m.streamingText)
+				m.streamingText = ""
+				return m.failRetake(partial)
+			}
+
+			// Keep what the guest already said; its speech is still queued.
+			if partial := strings.TrimSpace(m.streamingText); partial != "" {
+				record := m.guestRecord(partial)
+				record.Interrupted = true
+				m = m.recordMessage(record)
+			}
+			m.streamingText = ""
+
 			m.toastModel.AddError("AI stream error. Please try again.")
 			notice := "Sorry—looks like I'm having trouble reaching the AI right now. Want to try that again in a second?"
-			m.messages = append(m.messages, Message{Content: notice, Speaker: models.GUEST, Complete: true})
-			_ = storage.AppendMessage(m.id, "Guest", notice)
+			m = m.recordMessage(storage.Message{Speaker: models.GUEST, Content: notice})
 			return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 5*time.Second)
 		}
 
 		if msg.Event.Delta != "" {
+			if m.firstTokenAt.IsZero() {
+				m.firstTokenAt = time.Now()
+			}
 			speechDelta, blocks := m.consumeLLMDelta(msg.Event.Delta)
 			if speechDelta != "" {
 				m.streamingText += speechDelta
@@ -315,8 +467,13 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 				final = "Um—I'm blanking for a second. Could you rephrase that?"
 			}
 
-			m.messages = append(m.messages, Message{Content: final, Speaker: models.GUEST, Complete: true})
-			_ = storage.AppendMessage(m.id, "Guest", final)
+			record := m.guestRecord(final)
+			record.Truncated = msg.Event.FinishReason == "length"
+			if m.retakeOf != "" {
+				m = m.recordTake(record)
+			} else {
+				m = m.recordMessage(record)
+			}
 			m.streamingText = ""
 			m, ttsCmd := m.enqueueTTSBlocks(blocks)
 			return m, ttsCmd
@@ -331,21 +488,43 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 		}
 		return m, nil
 
+	case TTSSynthesizedMsg:
+		return m.finishTTS(msg)
+
 	case TTSSavedMsg:
+		m = m.savedTTS()
 		if msg.Err != nil || msg.Filename == "" {
 			if msg.Err != nil {
 				m.logger.LogError("tts_save", msg.Err)
 			}
-			m.ttsInFlight = false
-			return m.startNextTTS()
+			return m.advanceTTS()
 		}
-		if msg.Path != "" {
+		if msg.MessageID != "" && msg.Path != "" {
+			m.messageAudio[msg.MessageID] = append(m.messageAudio[msg.MessageID], msg.Path)
+			// A guest answer is written when it completes, so clips saved
+			// before then go into the record with it instead.
+			if _, err := storage.AddMessageAudio(m.id, msg.MessageID, msg.Filename); err != nil {
+				m.logger.LogError("storage_message_audio", err)
+			}
+		}
+		if msg.Path != "" && (msg.Speaker == models.GUEST || config.GetUIConfig().PlayHostAudio) {
 			if err := audio.Enqueue(msg.Path); err != nil {
 				m.logger.LogError("audio_enqueue", err)
+			} else if msg.Speaker == models.GUEST {
+				m.answerClips++
 			}
 		}
-		m.ttsInFlight = false
-		return m.startNextTTS()
+		return m.advanceTTS()
+
+	case AudioLevelMsg:
+		m.audioPlaying = msg.Level.Playing
+		if msg.Level.Playing {
+			m.wave.SetLevel(msg.Level.RMS)
+			m.wave.AdvancePhase(0.35)
+		} else {
+			m.wave.SetLevel(0)
+		}
+		return m, waitAudioLevelCmd()
 
 	case ToastDismissMsg:
 		m.toastModel.RemoveToast(msg.Index)
@@ -358,7 +537,27 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 		}
 
 	case tea.KeyMsg:
+		if m.selecting {
+			return m.updateSelecting(msg)
+		}
+		if m.confirmUndo {
+			return m.updateConfirmUndo(msg)
+		}
+		if m.editingID != "" {
+			switch {
+			case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+				return m.cancelEdit(), nil
+			case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+				return m.commitEdit(strings.TrimSpace(m.textInput.Value()))
+			}
+		}
 		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+o"))):
+			return m.startSelecting(), nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+g"))):
+			return m.regenerate()
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+z"))):
+			return m.startUndo()
 		case key.Matches(msg, key.NewBinding(key.WithKeys("tab"))):
 			if m.inputMode == "text" {
 				m.inputMode = "voice"
@@ -376,34 +575,68 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))):
 			m.logger.Info("conversation_quit", "conversation_id", m.id)
 			m.cancelInflightLLM()
+			m.cancelAllTTS()
 			audio.Drain()
 			m.EndConversation()
 			return m, tea.Quit
 		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+i"))):
 			m.showDetails = !m.showDetails
 			return m, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+p"))):
+			if err := audio.Start().TogglePause(); err != nil {
+				m.logger.LogError("audio_pause", err)
+				m.toastModel.AddError("Pausing playback isn't supported here.")
+				return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 5*time.Second)
+			}
+			return m, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+n"))):
+			audio.Start().Skip()
+			return m, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+x"))):
+			audio.Start().Clear()
+			return m, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+r"))):
+			if _, err := audio.Start().Replay(max(m.answerClips, 1)); err != nil {
+				m.logger.LogError("audio_replay", err)
+			}
+			return m, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+up"))):
+			player := audio.Start()
+			_, err := player.SetVolume(player.State().Volume + volumeStep)
+			return m, m.playerSettingFailed(err, "Your audio player can't change the volume.")
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+down"))):
+			player := audio.Start()
+			_, err := player.SetVolume(player.State().Volume - volumeStep)
+			return m, m.playerSettingFailed(err, "Your audio player can't change the volume.")
+		case key.Matches(msg, key.NewBinding(key.WithKeys("alt+up"))):
+			player := audio.Start()
+			_, err := player.SetSpeed(player.State().Speed + speedStep)
+			return m, m.playerSettingFailed(err, "Your audio player can't change the playback speed.")
+		case key.Matches(msg, key.NewBinding(key.WithKeys("alt+down"))):
+			player := audio.Start()
+			_, err := player.SetSpeed(player.State().Speed - speedStep)
+			return m, m.playerSettingFailed(err, "Your audio player can't change the playback speed.")
 		case key.Matches(msg, key.NewBinding(key.WithKeys("q"))):
 			if !m.isTyping && m.textInput.Value() == "" {
 				m.cancelInflightLLM()
+				m.cancelAllTTS()
 				audio.Drain()
 				m.EndConversation()
 				return m, tea.Quit
 			}
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			if !m.isTyping && m.inputMode == "voice" && strings.TrimSpace(m.sttDraft) != "" {
+				hostMsg := strings.TrimSpace(m.sttDraft)
+				m.logger.Info("host_voice_message_sent", "conversation_id", m.id, "message_length", len(hostMsg))
+				m.sttDraft = ""
+				return m.sendHostMessage(hostMsg)
+			}
 			if !m.isTyping && m.textInput.Value() != "" {
 				// Add host message
 				hostMsg := m.textInput.Value()
 				m.logger.Info("host_message_sent", "conversation_id", m.id, "message_length", len(hostMsg))
-				m.messages = append(m.messages, Message{
-					Content:  hostMsg,
-					Speaker:  models.HOST,
-					Complete: true,
-				})
-				if err := storage.AppendMessage(m.id, "Host", hostMsg); err != nil {
-					m.logger.LogError("storage_append_message", err)
-				}
 				m.textInput.Reset()
-				return m, m.startGuestResponse(false)
+				return m.sendHostMessage(hostMsg)
 			}
 		}
 	}
@@ -414,6 +647,497 @@ func (m ConversationModel) Update(msg tea.Msg) (ConversationModel, tea.Cmd) {
 	return m, cmd
 }
 
+// startSelecting highlights the latest message with audio, so earlier
+// answers can be picked and replayed.
+func (m ConversationModel) startSelecting() ConversationModel {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if len(m.messageAudio[m.messages[i].ID]) > 0 {
+			m.selecting = true
+			m.focusID = m.messages[i].ID
+			return m
+		}
+	}
+	if len(m.messages) > 0 {
+		m.selecting = true
+		m.focusID = m.messages[len(m.messages)-1].ID
+	}
+	return m
+}
+
+// updateSelecting moves through past messages and plays the chosen one's
+// clips in place of whatever is queued.
+func (m ConversationModel) updateSelecting(msg tea.KeyMsg) (ConversationModel, tea.Cmd) {
+	current := -1
+	for i, message := range m.messages {
+		if message.ID == m.focusID {
+			current = i
+			break
+		}
+	}
+
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))):
+		m.selecting = false
+		m.focusID = ""
+		m = m.showCanonicalTakes()
+		return m.Update(msg)
+	case key.Matches(msg, key.NewBinding(key.WithKeys("esc", "ctrl+o"))):
+		m.selecting = false
+		m.focusID = ""
+		m = m.showCanonicalTakes()
+	case key.Matches(msg, key.NewBinding(key.WithKeys("left", "h"))):
+		if current >= 0 {
+			m = m.cycleTake(current, -1)
+		}
+	case key.Matches(msg, key.NewBinding(key.WithKeys("right", "l"))):
+		if current >= 0 {
+			m = m.cycleTake(current, 1)
+		}
+	case key.Matches(msg, key.NewBinding(key.WithKeys("c"))):
+		if current >= 0 {
+			return m.chooseTake(current)
+		}
+	case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+		if current > 0 {
+			m.focusID = m.messages[current-1].ID
+		}
+	case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+		if current >= 0 && current < len(m.messages)-1 {
+			m.focusID = m.messages[current+1].ID
+		}
+	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+		clips := m.messageAudio[m.focusID]
+		if len(clips) == 0 {
+			m.toastModel.AddError("That message has no audio.")
+			return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 3*time.Second)
+		}
+		audio.Start().Clear()
+		for _, clip := range clips {
+			if err := audio.Enqueue(clip); err != nil {
+				m.logger.LogError("audio_enqueue", err)
+				break
+			}
+		}
+		m.logger.Info("message_audio_replayed", "conversation_id", m.id, "message_id", m.focusID, "clips", len(clips))
+	case key.Matches(msg, key.NewBinding(key.WithKeys("f"))):
+		m = m.showCanonicalTakes()
+		return m.fork()
+	case key.Matches(msg, key.NewBinding(key.WithKeys("e"))):
+		if current >= 0 && m.messages[current].Speaker == models.HOST {
+			m.selecting = false
+			m = m.showCanonicalTakes()
+			return m.startEdit(m.messages[current])
+		}
+	}
+	return m, nil
+}
+
+// busy reports whether an answer is still streaming or being voiced, when
+// the transcript mustn't be rewritten under it.
+func (m ConversationModel) busy() bool {
+	return m.isTyping || len(m.ttsQueue) > 0 || len(m.ttsJobs) > 0
+}
+
+// startEdit puts a sent host message into the input for correcting.
+func (m ConversationModel) startEdit(msg Message) (ConversationModel, tea.Cmd) {
+	if m.busy() {
+		m.focusID = ""
+		m.toastModel.AddError("Wait for the answer to finish before editing.")
+		return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 3*time.Second)
+	}
+	m.editingID = msg.ID
+	m.focusID = msg.ID
+	m.inputMode = "text"
+	m.textInput.SetValue(msg.Content)
+	m.textInput.CursorEnd()
+	m.textInput.Focus()
+	return m, nil
+}
+
+func (m ConversationModel) cancelEdit() ConversationModel {
+	m.editingID = ""
+	m.focusID = ""
+	m.textInput.Reset()
+	return m
+}
+
+// commitEdit replaces the edited message and everything after it with the
+// corrected question, then asks the guest to answer it.
+func (m ConversationModel) commitEdit(text string) (ConversationModel, tea.Cmd) {
+	if text == "" {
+		return m, nil
+	}
+	for _, msg := range m.messages {
+		if msg.ID == m.editingID && msg.Content == text {
+			return m.cancelEdit(), nil
+		}
+	}
+	if m.busy() {
+		m.toastModel.AddError("Wait for the answer to finish before editing.")
+		return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 3*time.Second)
+	}
+
+	editedID := m.editingID
+	var err error
+	if m, err = m.rewind(editedID); err != nil {
+		m.logger.LogError("conversation_edit", err)
+		m.toastModel.AddError("Couldn't edit the message.")
+		return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 5*time.Second)
+	}
+	m.editingID = ""
+	m.textInput.Reset()
+	m.logger.Info("host_message_edited", "conversation_id", m.id, "message_id", editedID, "message_length", len(text))
+	return m.sendHostMessage(text)
+}
+
+// startUndo asks before removing the last host question and the guest's
+// answer to it.
+func (m ConversationModel) startUndo() (ConversationModel, tea.Cmd) {
+	if m.busy() {
+		m.toastModel.AddError("Wait for the answer to finish before undoing.")
+		return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 3*time.Second)
+	}
+	if m.lastHostIndex() < 0 {
+		m.toastModel.AddError("Nothing to undo.")
+		return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 3*time.Second)
+	}
+	m.confirmUndo = true
+	m.focusID = m.messages[m.lastHostIndex()].ID
+	return m, nil
+}
+
+func (m ConversationModel) updateConfirmUndo(msg tea.KeyMsg) (ConversationModel, tea.Cmd) {
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("y"))):
+		m.confirmUndo = false
+		m.focusID = ""
+		i := m.lastHostIndex()
+		if i < 0 || m.busy() {
+			return m, nil
+		}
+		undoneID := m.messages[i].ID
+		var err error
+		if m, err = m.rewind(undoneID); err != nil {
+			m.logger.LogError("conversation_undo", err)
+			m.toastModel.AddError("Couldn't undo the last exchange.")
+			return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 5*time.Second)
+		}
+		m.logger.Info("exchange_undone", "conversation_id", m.id, "message_id", undoneID)
+	case key.Matches(msg, key.NewBinding(key.WithKeys("n", "esc"))):
+		m.confirmUndo = false
+		m.focusID = ""
+	case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))):
+		m.confirmUndo = false
+		return m.Update(msg)
+	}
+	return m, nil
+}
+
+func (m ConversationModel) lastHostIndex() int {
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].Speaker == models.HOST {
+			return i
+		}
+	}
+	return -1
+}
+
+// rewind removes a message and everything after it from the conversation,
+// with their takes and audio.
+func (m ConversationModel) rewind(messageID string) (ConversationModel, error) {
+	// Stop playback first; queued clips may be among those deleted.
+	audio.Start().Clear()
+	m.answerClips = 0
+
+	removed, err := library.Rewind(m.db, m.id, messageID)
+	if err != nil {
+		return m, err
+	}
+
+	for i, msg := range m.messages {
+		if msg.ID == messageID {
+			m.messages = m.messages[:i]
+			break
+		}
+	}
+	for _, msg := range removed {
+		delete(m.messageAudio, msg.ID)
+		delete(m.takes, msg.TakeGroup())
+	}
+	return m, nil
+}
+
+// regenerate asks the guest for another take of the latest answer. The
+// earlier takes are kept as alternates.
+func (m ConversationModel) regenerate() (ConversationModel, tea.Cmd) {
+	if m.isTyping || len(m.ttsQueue) > 0 || len(m.ttsJobs) > 0 {
+		m.toastModel.AddError("Wait for the answer to finish before regenerating it.")
+		return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 3*time.Second)
+	}
+	if len(m.messages) == 0 || m.messages[len(m.messages)-1].Speaker != models.GUEST {
+		m.toastModel.AddError("Only the guest's latest answer can be regenerated.")
+		return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 3*time.Second)
+	}
+
+	last := m.messages[len(m.messages)-1]
+	m.retakeOf = last.takeGroup()
+	m.retakePrev = last
+	m.messages = m.messages[:len(m.messages)-1]
+	m.focusID = ""
+	m.logger.Info("guest_answer_regenerated", "conversation_id", m.id, "message_id", last.ID)
+
+	isFirst := true
+	for _, msg := range m.messages {
+		if msg.Speaker == models.HOST {
+			isFirst = false
+			break
+		}
+	}
+	return m, m.startGuestResponse(isFirst)
+}
+
+// recordTake saves a finished retake as the answer's canonical take.
+func (m ConversationModel) recordTake(record storage.Message) ConversationModel {
+	group := m.retakeOf
+	record.TakeOf = group
+	m = m.recordMessage(record)
+	take := m.messages[len(m.messages)-1]
+	if err := storage.SetCanonicalTake(m.db, m.id, take.ID); err != nil {
+		m.logger.LogError("storage_set_canonical_take", err)
+	}
+
+	takes := m.takes[group]
+	if len(takes) == 0 {
+		takes = []Message{m.retakePrev}
+	}
+	for i := range takes {
+		takes[i].Alternate = true
+	}
+	m.takes[group] = append(takes, take)
+	m.retakeOf = ""
+	return m
+}
+
+// failRetake puts the earlier take back after a retake couldn't be
+// generated. Whatever the guest managed to say is kept as an alternate.
+func (m ConversationModel) failRetake(partial string) (ConversationModel, tea.Cmd) {
+	group := m.retakeOf
+	m.retakeOf = ""
+	m.messages = append(m.messages, m.retakePrev)
+
+	if partial != "" {
+		record := m.guestRecord(partial)
+		record.Interrupted = true
+		record.TakeOf = group
+		record.Alternate = true
+		if _, err := storage.AppendRecord(m.db, m.id, record); err != nil {
+			m.logger.LogError("storage_append_message", err)
+		} else {
+			takes := m.takes[group]
+			if len(takes) == 0 {
+				takes = []Message{m.retakePrev}
+			}
+			m.takes[group] = append(takes, Message{
+				ID:        record.ID,
+				Content:   record.Content,
+				Speaker:   record.Speaker,
+				Complete:  true,
+				TakeOf:    group,
+				Alternate: true,
+			})
+		}
+	}
+
+	m.toastModel.AddError("Couldn't regenerate the answer. The earlier take is kept.")
+	return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 5*time.Second)
+}
+
+// cycleTake shows the previous or next take of the message at i.
+func (m ConversationModel) cycleTake(i, step int) ConversationModel {
+	takes := m.takes[m.messages[i].takeGroup()]
+	if len(takes) < 2 {
+		return m
+	}
+	current := 0
+	for j, take := range takes {
+		if take.ID == m.messages[i].ID {
+			current = j
+			break
+		}
+	}
+	next := (current + step + len(takes)) % len(takes)
+	m.messages[i] = takes[next]
+	m.focusID = takes[next].ID
+	return m
+}
+
+// chooseTake makes the take shown at i the canonical one.
+func (m ConversationModel) chooseTake(i int) (ConversationModel, tea.Cmd) {
+	chosen := m.messages[i]
+	group := chosen.takeGroup()
+	takes := m.takes[group]
+	if len(takes) < 2 || !chosen.Alternate {
+		return m, nil
+	}
+
+	if err := storage.SetCanonicalTake(m.db, m.id, chosen.ID); err != nil {
+		m.logger.LogError("storage_set_canonical_take", err)
+		m.toastModel.AddError("Couldn't save the chosen take.")
+		return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 5*time.Second)
+	}
+	for j := range takes {
+		takes[j].Alternate = takes[j].ID != chosen.ID
+	}
+	chosen.Alternate = false
+	m.messages[i] = chosen
+	m.logger.Info("canonical_take_chosen", "conversation_id", m.id, "message_id", chosen.ID)
+	return m, nil
+}
+
+// showCanonicalTakes puts back the canonical take of any answer whose
+// alternate was being previewed.
+func (m ConversationModel) showCanonicalTakes() ConversationModel {
+	for i, msg := range m.messages {
+		if !msg.Alternate {
+			continue
+		}
+		for _, take := range m.takes[msg.takeGroup()] {
+			if !take.Alternate {
+				m.messages[i] = take
+				break
+			}
+		}
+	}
+	return m
+}
+
+// fork copies the conversation up to the selected message into a new one
+// and asks the app to switch to it. The original is left as it was.
+func (m ConversationModel) fork() (ConversationModel, tea.Cmd) {
+	if m.isTyping || len(m.ttsQueue) > 0 || len(m.ttsJobs) > 0 {
+		m.toastModel.AddError("Wait for the answer to finish before forking.")
+		return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 3*time.Second)
+	}
+
+	// A fork copies the conversation as it is played, so while an alternate
+	// take is previewed it is cut at that answer's canonical take.
+	messageID := m.focusID
+	for _, msg := range m.messages {
+		if msg.ID != messageID || !msg.Alternate {
+			continue
+		}
+		for _, take := range m.takes[msg.takeGroup()] {
+			if !take.Alternate {
+				messageID = take.ID
+				break
+			}
+		}
+		break
+	}
+
+	fork, err := library.Fork(m.db, m.id, messageID)
+	if err == nil {
+		var conv *db.Conversation
+		if conv, err = m.db.GetConversation(fork.ID); err == nil {
+			m.logger.Info("conversation_forked", "conversation_id", m.id, "message_id", messageID, "fork_id", fork.ID)
+			m.selecting = false
+			audio.Start().Clear()
+			m.EndConversation()
+			forked := *conv
+			return m, func() tea.Msg { return ConversationForkedMsg{Conversation: forked} }
+		}
+	}
+
+	m.logger.LogError("conversation_fork", err)
+	m.toastModel.AddError("Couldn't fork the conversation.")
+	return m, DismissToastCmd(len(m.toastModel.GetToasts())-1, 5*time.Second)
+}
+
+// takeLabel numbers the take shown for an answer with retakes, e.g.
+// " take 2/3 ✓" for the canonical second of three.
+func (m ConversationModel) takeLabel(msg Message) string {
+	takes := m.takes[msg.takeGroup()]
+	if len(takes) < 2 {
+		return ""
+	}
+	for i, take := range takes {
+		if take.ID == msg.ID {
+			label := fmt.Sprintf(" take %d/%d", i+1, len(takes))
+			if !msg.Alternate {
+				label += " ✓"
+			}
+			return label
+		}
+	}
+	return ""
+}
+
+// sendHostMessage records a host turn, queues it for the host voice and
+// asks the guest to respond.
+func (m ConversationModel) sendHostMessage(hostMsg string) (ConversationModel, tea.Cmd) {
+	m.focusID = ""
+	m = m.recordMessage(storage.Message{Speaker: models.HOST, Content: hostMsg})
+
+	var ttsCmd tea.Cmd
+	if m.voiceHost {
+		hostID := m.messages[len(m.messages)-1].ID
+		m.ttsQueue = append(m.ttsQueue, ttsItem{text: hostMsg, speaker: models.HOST, messageID: hostID})
+		m, ttsCmd = m.startNextTTS()
+	}
+	return m, tea.Batch(ttsCmd, m.startGuestResponse(false))
+}
+
+// guestRecord describes the answer being streamed for the transcript.
+func (m ConversationModel) guestRecord(content string) storage.Message {
+	now := time.Now()
+	record := storage.Message{
+		ID:        m.answerID,
+		Timestamp: now,
+		Speaker:   models.GUEST,
+		Content:   content,
+		Provider:  m.provider,
+	}
+	record.Model, _ = config.GetProviderChatModel(m.provider)
+	for _, path := range m.messageAudio[m.answerID] {
+		record.Audio = append(record.Audio, filepath.Base(path))
+	}
+	if !m.answerStarted.IsZero() {
+		started := m.answerStarted
+		record.StartedAt = &started
+		record.DurationMs = now.Sub(started).Milliseconds()
+		if !m.firstTokenAt.IsZero() {
+			record.FirstTokenMs = m.firstTokenAt.Sub(started).Milliseconds()
+		}
+	}
+	return record
+}
+
+// FocusMessage scrolls the transcript to a message and highlights it until
+// the next turn. An unknown id leaves the view at the latest messages.
+func (m ConversationModel) FocusMessage(id string) ConversationModel {
+	m.focusID = id
+	return m
+}
+
+// recordMessage shows a completed turn and saves it to the transcript.
+func (m ConversationModel) recordMessage(record storage.Message) ConversationModel {
+	if record.ID == "" {
+		record.ID = uuid.New().String()
+	}
+	if _, err := storage.AppendRecord(m.db, m.id, record); err != nil {
+		m.logger.LogError("storage_append_message", err)
+	}
+	m.messages = append(m.messages, Message{
+		ID:        record.ID,
+		Content:   record.Content,
+		Speaker:   record.Speaker,
+		Complete:  true,
+		TakeOf:    record.TakeOf,
+		Alternate: record.Alternate,
+	})
+	return m
+}
+
 func (m ConversationModel) waitLLMEventCmd() tea.Cmd {
 	stream := m.llmStream
 	return func() tea.Msg {
@@ -428,6 +1152,20 @@ func (m ConversationModel) waitLLMEventCmd() tea.Cmd {
 	}
 }
 
+// Step sizes for the playback volume and speed keys.
+const (
+	volumeStep = 0.1
+	speedStep  = 0.25
+)
+
+// waitAudioLevelCmd waits for the next reading from the audio player.
+func waitAudioLevelCmd() tea.Cmd {
+	levels := audio.Levels()
+	return func() tea.Msg {
+		return AudioLevelMsg{Level: <-levels}
+	}
+}
+
 func (m *ConversationModel) resetLLMParser() {
 	m.llmRawBuffer = ""
 	m.llmInSpeech = false
@@ -531,6 +1269,17 @@ func partialTagSuffix(buffer string, tag string) int {
 	return 0
 }
 
+// playerSettingFailed shows a toast when the audio player refused a volume
+// or speed change.
+func (m *ConversationModel) playerSettingFailed(err error, message string) tea.Cmd {
+	if err == nil {
+		return nil
+	}
+	m.logger.LogError("audio_setting", err)
+	m.toastModel.AddError(message)
+	return DismissToastCmd(len(m.toastModel.GetToasts())-1, 5*time.Second)
+}
+
 func (m *ConversationModel) cancelInflightLLM() {
 	if m.llmCancel != nil {
 		m.llmCancel()
@@ -559,60 +1308,6 @@ func (m ConversationModel) toChatHistory(isFirst bool) []llm.ChatMessage {
 	return history
 }
 
-func (m ConversationModel) ttsCmd(text string) tea.Cmd {
-	// Keep TTS best-effort; conversation should work without it.
-	ttsProvider := config.GetTextToSpeechProvider()
-	if strings.TrimSpace(ttsProvider) == "" {
-		ttsProvider = "openai"
-	}
-
-	if url, err := config.GetProviderTTSURL(ttsProvider); err != nil || strings.TrimSpace(url) == "" {
-		if _, err := config.GetProviderConfig("openai"); err == nil {
-			ttsProvider = "openai"
-		}
-	}
-
-	voiceID := m.voice.ID
-	persona := m.persona
-	topic := m.topic
-	conversationID := m.id
-	client := m.llmClient
-
-	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-		defer cancel()
-
-		cleanText := normalizeTTSInput(text)
-		audioData, _, err := client.SynthesizeGuestSpeech(ctx, "", ttsProvider, persona, topic, voiceID, cleanText)
-		if err != nil {
-			return TTSSavedMsg{Err: err}
-		}
-		filename, err := storage.SaveAudio(conversationID, audioData)
-		if err != nil {
-			return TTSSavedMsg{Err: err}
-		}
-		audioDir, err := storage.GetAudioDir(conversationID)
-		if err != nil {
-			return TTSSavedMsg{Filename: filename, Err: err}
-		}
-		return TTSSavedMsg{Filename: filename, Path: filepath.Join(audioDir, filename)}
-	}
-}
-
-func (m ConversationModel) enqueueTTSBlocks(blocks []string) (ConversationModel, tea.Cmd) {
-	if len(blocks) == 0 {
-		return m, nil
-	}
-	for _, block := range blocks {
-		trimmed := strings.TrimSpace(block)
-		if trimmed == "" {
-			continue
-		}
-		m.ttsQueue = append(m.ttsQueue, trimmed)
-	}
-	return m.startNextTTS()
-}
-
 func normalizeTTSInput(text string) string {
 	clean := stripHTMLTags(text)
 	clean = ensureSentenceSpacing(clean)
@@ -761,23 +1456,6 @@ func wrapText(text string, width int) []string {
 	return lines
 }
 
-func (m ConversationModel) startNextTTS() (ConversationModel, tea.Cmd) {
-	if m.ttsInFlight || len(m.ttsQueue) == 0 {
-		return m, nil
-	}
-	chunk := strings.TrimSpace(m.ttsQueue[0])
-	m.ttsQueue = m.ttsQueue[1:]
-	if chunk == "" {
-		return m.startNextTTS()
-	}
-	if chunk == m.ttsLastChunk {
-		return m.startNextTTS()
-	}
-	m.ttsInFlight = true
-	m.ttsLastChunk = chunk
-	return m, m.ttsCmd(chunk)
-}
-
 func (m ConversationModel) dotAnimationCmd() tea.Cmd {
 	return tea.Tick(50*time.Millisecond, func(t time.Time) tea.Msg {
 		return DotAnimationMsg{}
@@ -875,6 +1553,39 @@ func preRenderAnimationFrames() []string {
 	return frames
 }
 
+// renderPlayerState summarizes the audio player for the meta line,
+// e.g. "▶ 003.wav +2 queued | vol 80% | 1.25x".
+func renderPlayerState(state audio.PlayerState) string {
+	status := "■ idle"
+	switch {
+	case state.Paused:
+		status = "⏸ paused"
+		if state.Current != "" {
+			status += " " + filepath.Base(state.Current)
+		}
+	case state.Current != "":
+		status = "▶ " + filepath.Base(state.Current)
+	}
+	if state.Queued > 0 {
+		status += fmt.Sprintf(" +%d queued", state.Queued)
+	}
+	return fmt.Sprintf("%s | vol %d%% | %gx", status, int(math.Round(state.Volume*100)), state.Speed)
+}
+
+// scrollTranscript keeps the transcript within height lines: the latest
+// lines, or from focusLine onwards when a message is focused.
+func scrollTranscript(content string, height, focusLine int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if height <= 0 || len(lines) <= height {
+		return content
+	}
+	start := len(lines) - height
+	if focusLine >= 0 && focusLine < start {
+		start = focusLine
+	}
+	return strings.Join(lines[start:start+height], "\n")
+}
+
 // renderFlowingDots returns pre-rendered animation frame
 func (m ConversationModel) renderFlowingDots() string {
 	return animationFrames[m.dotFrame%len(animationFrames)]
@@ -887,6 +1598,9 @@ func (m ConversationModel) renderConversation() string {
 	inputMetaHeight := 1
 	animationHeight := 1
 	helpHeight := 1
+	if m.showWave {
+		helpHeight++
+	}
 	padding := 4 // spacing between elements
 
 	// Calculate transcript height to fill remaining space
@@ -896,10 +1610,19 @@ func (m ConversationModel) renderConversation() string {
 	}
 	transcriptHeight := m.height - logoHeight - bottomHeight - 2
 
+	waveHeight := 0
+	if m.showWave {
+		waveHeight = m.wave.Height()
+		transcriptHeight -= waveHeight + 1
+	}
+
 	// Adjust height if showing details
 	detailsHeight := 0
 	if m.showDetails {
 		detailsHeight = 2 // Topic and Persona lines
+		if m.parentTitle != "" {
+			detailsHeight++ // Forked from
+		}
 		transcriptHeight -= detailsHeight
 	}
 
@@ -911,12 +1634,24 @@ func (m ConversationModel) renderConversation() string {
 	if contentWidth < 20 {
 		contentWidth = 20
 	}
+	focusLine := -1
 	for _, msg := range m.messages {
+		labelStyle, label := styles.GuestLabelStyle, "GUEST"
 		if msg.Speaker == models.HOST {
-			transcriptView.WriteString(renderTranscriptMessage(styles.HostLabelStyle, "HOST", msg.Content, contentWidth))
-		} else {
-			transcriptView.WriteString(renderTranscriptMessage(styles.GuestLabelStyle, "GUEST", msg.Content, contentWidth))
+			labelStyle, label = styles.HostLabelStyle, "HOST"
+		}
+		if len(m.messageAudio[msg.ID]) > 0 {
+			label += " ♪"
+		}
+		label += m.takeLabel(msg)
+		if msg.Alternate {
+			labelStyle = labelStyle.Faint(true)
 		}
+		if m.focusID != "" && msg.ID == m.focusID {
+			focusLine = strings.Count(transcriptView.String(), "\n")
+			labelStyle = labelStyle.Reverse(true)
+		}
+		transcriptView.WriteString(renderTranscriptMessage(labelStyle, label, msg.Content, contentWidth))
 	}
 
 	// Add streaming message if typing
@@ -925,7 +1660,7 @@ func (m ConversationModel) renderConversation() string {
 	}
 
 	// Create transcript container
-	transcriptContent := transcriptView.String()
+	transcriptContent := scrollTranscript(transcriptView.String(), transcriptHeight, focusLine)
 	transcriptContainer := styles.TranscriptPanelStyle.
 		Height(transcriptHeight).
 		Render(transcriptContent)
@@ -952,9 +1687,26 @@ func (m ConversationModel) renderConversation() string {
 	providerStyle := lipgloss.NewStyle().Foreground(styles.MutedColor)
 	modeMeta := fmt.Sprintf("%s %s", modeStyle.Render(strings.ToUpper(m.inputMode)), providerStyle.Render(m.provider))
 	metaLine := fmt.Sprintf("  %s", modeMeta)
+	if m.showWave {
+		metaLine += "  " + providerStyle.Render(renderPlayerState(audio.Start().State()))
+	}
 
 	// Help text
-	help := styles.HelpStyle.Render("  Tab toggle input | m mute | Enter to send | Ctrl+I show/hide details | q or Ctrl+C to exit")
+	help := styles.HelpStyle.Render("  Tab toggle input | m mute | Enter to send | Ctrl+G regenerate answer | Ctrl+Z undo | Ctrl+I show/hide details | q or Ctrl+C to exit")
+	if m.selecting {
+		help = styles.HelpStyle.Render("  ↑/↓ select message | ←/→ takes | c keep this take | e edit | Enter play its audio | f fork from here | Esc or Ctrl+O done")
+	} else if m.confirmUndo {
+		help = lipgloss.NewStyle().Foreground(styles.ErrorColor).Bold(true).
+			Render("  Remove your last question and everything after it, with its audio? y/n")
+	} else if m.editingID != "" {
+		help = styles.HelpStyle.Render("  Enter to resend; later replies are removed and the guest answers again | Esc to cancel")
+	} else if m.showWave {
+		help = lipgloss.JoinVertical(
+			lipgloss.Left,
+			help,
+			styles.HelpStyle.Render("  Ctrl+P pause/resume | Ctrl+N skip | Ctrl+X clear | Ctrl+R replay | Ctrl+O replay or fork from a message | Ctrl+↑/↓ volume | Alt+↑/↓ speed"),
+		)
+	}
 
 	// Build bottom section: input first, then animation below (both anchored to bottom)
 	var bottomSection string
@@ -993,6 +1745,13 @@ func (m ConversationModel) renderConversation() string {
 			fmt.Sprintf("%s %s", mutedStyle.Render("Topic:"), textStyle.Render(m.topic)),
 			fmt.Sprintf("%s %s", mutedStyle.Render("Persona:"), textStyle.Render(m.persona)),
 		)
+		if m.parentTitle != "" {
+			details = lipgloss.JoinVertical(
+				lipgloss.Left,
+				details,
+				fmt.Sprintf("%s %s", mutedStyle.Render("Forked from:"), textStyle.Render(m.parentTitle)),
+			)
+		}
 		topSection = lipgloss.JoinVertical(
 			lipgloss.Left,
 			styles.LogoWithTitle(m.title),
@@ -1003,15 +1762,15 @@ func (m ConversationModel) renderConversation() string {
 		topSection = styles.LogoWithTitle(m.title)
 	}
 
-	// Combine: logo (with optional details), transcript, then bottom section anchored to bottom
-	content := lipgloss.JoinVertical(
-		lipgloss.Left,
-		topSection,
-		"",
-		transcriptContainer,
-		"",
-		bottomSection,
-	)
+	// Combine: logo (with optional details), transcript, wave, then bottom section anchored to bottom
+	sections := []string{topSection, "", transcriptContainer, ""}
+	if m.showWave {
+		waveLines := m.wave.Render(contentWidth, waveHeight)
+		waveArea := lipgloss.NewStyle().PaddingLeft(2).Render(styles.WaveStyle.Render(strings.Join(waveLines, "\n")))
+		sections = append(sections, waveArea, "")
+	}
+	sections = append(sections, bottomSection)
+	content := lipgloss.JoinVertical(lipgloss.Left, sections...)
 
 	return content
 }
@@ -1039,3 +1798,9 @@ func (m ConversationModel) EndConversation() error {
 
 // ExitConversationMsg signals to exit the conversation
 type ExitConversationMsg struct{}
+
+// ConversationForkedMsg is sent when a fork has been created and should be
+// opened in place of the current conversation.
+type ConversationForkedMsg struct {
+	Conversation db.Conversation
+}
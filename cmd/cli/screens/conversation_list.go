@@ -2,49 +2,149 @@ package screens
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/nraghuveer/vibecast/cmd/cli/styles"
 	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/library"
 	"github.com/nraghuveer/vibecast/lib/logger"
+	"github.com/nraghuveer/vibecast/lib/models"
+	"github.com/nraghuveer/vibecast/lib/script"
+)
+
+const (
+	searchLimit   = 50
+	searchVisible = 6 // results shown at once
+)
+
+// listAction is a prompt open on the selected conversation.
+type listAction int
+
+const (
+	listActionNone listAction = iota
+	listActionRename
+	listActionDelete
+	listActionExport
 )
 
 // ConversationListModel displays a list of existing conversations
 type ConversationListModel struct {
 	db            *db.DB
-	conversations []db.Conversation
+	all           []db.Conversation // including archived ones
+	conversations []db.Conversation // the ones listed
 	cursor        int
 	showDetails   bool // Toggle for showing topic/persona (Ctrl+I)
+	showArchived  bool // Toggle for listing archived conversations (A)
 	width         int
 	height        int
 	err           error
 	logger        *logger.Logger
+
+	// Rename, delete and export prompts on the selected conversation
+	action      listAction
+	renameInput textinput.Model
+	notice      string
+	noticeErr   bool
+
+	// Message search, opened with "/"
+	searching    bool
+	searchInput  textinput.Model
+	results      []models.MessageMatch
+	resultCursor int
+	searchErr    error
 }
 
-// ConversationSelectedMsg is sent when a conversation is selected
+// ConversationSelectedMsg is sent when a conversation is selected.
+// MessageID is set when it was opened from a search result.
 type ConversationSelectedMsg struct {
 	Conversation db.Conversation
+	MessageID    string
 }
 
 func NewConversationListModel(database *db.DB) ConversationListModel {
 	log := logger.GetInstance()
-	conversations, err := database.GetAllConversations()
+
+	si := textinput.New()
+	si.Placeholder = "Search messages..."
+	si.Prompt = "/ "
+
+	ri := textinput.New()
+	ri.Placeholder = "Conversation title"
+	ri.Prompt = "Title: "
+	ri.CharLimit = 120
+
+	m := ConversationListModel{
+		db:          database,
+		cursor:      0,
+		showDetails: false,
+		logger:      log,
+		searchInput: si,
+		renameInput: ri,
+	}
+	m = m.reload("")
+	if m.err == nil {
+		log.Info("conversation_list_loaded", "count", len(m.all))
+	}
+	return m
+}
+
+// reload re-reads the conversations and lists the ones the archive filter
+// allows, keeping selectID (or else the current position) selected.
+func (m ConversationListModel) reload(selectID string) ConversationListModel {
+	all, err := m.db.GetAllConversations()
 	if err != nil {
-		log.LogError("conversation_list_load", err)
-	} else {
-		log.Info("conversation_list_loaded", "count", len(conversations))
+		m.logger.LogError("conversation_list_load", err)
+	}
+	m.all = all
+	m.err = err
+
+	m.conversations = nil
+	for _, c := range all {
+		if m.showArchived || !c.ArchivedAt.Valid {
+			m.conversations = append(m.conversations, c)
+		}
+	}
+
+	for i, c := range m.conversations {
+		if c.ID == selectID {
+			m.cursor = i
+			return m
+		}
 	}
+	m.cursor = max(0, min(m.cursor, len(m.conversations)-1))
+	return m
+}
+
+func (m ConversationListModel) selected() (db.Conversation, bool) {
+	if len(m.conversations) == 0 {
+		return db.Conversation{}, false
+	}
+	return m.conversations[m.cursor], true
+}
 
-	return ConversationListModel{
-		db:            database,
-		conversations: conversations,
-		cursor:        0,
-		showDetails:   false,
-		err:           err,
-		logger:        log,
+// parentTitle returns the title of the conversation conv was forked from,
+// or "" if it isn't a fork or the parent is gone.
+func (m ConversationListModel) parentTitle(conv db.Conversation) string {
+	if conv.ParentID == "" {
+		return ""
 	}
+	for _, c := range m.all {
+		if c.ID == conv.ParentID {
+			return c.Title
+		}
+	}
+	return ""
+}
+
+func (m ConversationListModel) setNotice(text string, isErr bool) ConversationListModel {
+	m.notice = text
+	m.noticeErr = isErr
+	return m
 }
 
 func (m ConversationListModel) Init() tea.Cmd {
@@ -58,11 +158,25 @@ func (m ConversationListModel) Update(msg tea.Msg) (ConversationListModel, tea.C
 		m.height = msg.Height
 
 	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		if m.action != listActionNone {
+			return m.updateAction(msg)
+		}
+		m.notice = ""
 		switch {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))):
 			m.logger.Info("conversation_list_quit")
 			return m, tea.Quit
 
+		case key.Matches(msg, key.NewBinding(key.WithKeys("/"))):
+			if len(m.all) > 0 {
+				m.searching = true
+				m.searchInput.Width = max(m.width-20, 20)
+				return m, m.searchInput.Focus()
+			}
+
 		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
 			m.logger.Info("conversation_list_back_to_welcome")
 			return m, func() tea.Msg { return BackToWelcomeMsg{} }
@@ -71,6 +185,45 @@ func (m ConversationListModel) Update(msg tea.Msg) (ConversationListModel, tea.C
 			m.showDetails = !m.showDetails
 			return m, nil
 
+		case key.Matches(msg, key.NewBinding(key.WithKeys("A"))):
+			m.showArchived = !m.showArchived
+			current, _ := m.selected()
+			return m.reload(current.ID), nil
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
+			if conv, ok := m.selected(); ok {
+				m.action = listActionRename
+				m.renameInput.SetValue(conv.Title)
+				m.renameInput.CursorEnd()
+				m.renameInput.Width = max(m.width-30, 20)
+				return m, m.renameInput.Focus()
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("d"))):
+			if _, ok := m.selected(); ok {
+				m.action = listActionDelete
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("a"))):
+			if conv, ok := m.selected(); ok {
+				return m.toggleArchived(conv), nil
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("s"))):
+			if conv, ok := m.selected(); ok {
+				return m.toggleStarred(conv), nil
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("c"))):
+			if conv, ok := m.selected(); ok {
+				return m.duplicate(conv), nil
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("x"))):
+			if _, ok := m.selected(); ok {
+				m.action = listActionExport
+			}
+
 		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
 			if m.cursor > 0 {
 				m.cursor--
@@ -98,7 +251,199 @@ func (m ConversationListModel) Update(msg tea.Msg) (ConversationListModel, tea.C
 	return m, nil
 }
 
+// updateAction handles keys while a rename, delete or export prompt is open.
+func (m ConversationListModel) updateAction(msg tea.KeyMsg) (ConversationListModel, tea.Cmd) {
+	if key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))) {
+		m.logger.Info("conversation_list_quit")
+		return m, tea.Quit
+	}
+	conv, ok := m.selected()
+	if !ok {
+		m.action = listActionNone
+		return m, nil
+	}
+
+	switch m.action {
+	case listActionRename:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+			m.action = listActionNone
+			m.renameInput.Blur()
+			return m, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			title := strings.TrimSpace(m.renameInput.Value())
+			if title == "" {
+				return m.setNotice("Title can't be empty", true), nil
+			}
+			m.action = listActionNone
+			m.renameInput.Blur()
+			if err := m.db.RenameConversation(conv.ID, title); err != nil {
+				m.logger.LogError("conversation_rename", err)
+				return m.setNotice(fmt.Sprintf("Rename failed: %v", err), true), nil
+			}
+			m.logger.Info("conversation_renamed", "id", conv.ID, "title", title)
+			return m.reload(conv.ID).setNotice(fmt.Sprintf("Renamed to %q", title), false), nil
+		}
+		var cmd tea.Cmd
+		m.renameInput, cmd = m.renameInput.Update(msg)
+		return m, cmd
+
+	case listActionDelete:
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("y", "Y"))):
+			m.action = listActionNone
+			err := library.Delete(m.db, conv.ID)
+			m = m.reload("")
+			if err != nil {
+				m.logger.LogError("conversation_delete", err)
+				return m.setNotice(fmt.Sprintf("Delete failed: %v", err), true), nil
+			}
+			m.logger.Info("conversation_deleted", "id", conv.ID)
+			return m.setNotice(fmt.Sprintf("Deleted %q", conv.Title), false), nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("n", "N", "esc"))):
+			m.action = listActionNone
+		}
+
+	case listActionExport:
+		formats := map[string]string{"m": script.FormatMarkdown, "h": script.FormatHTML, "t": script.FormatText}
+		if format, ok := formats[msg.String()]; ok {
+			m.action = listActionNone
+			return m.exportScript(conv, format), nil
+		}
+		if key.Matches(msg, key.NewBinding(key.WithKeys("esc"))) {
+			m.action = listActionNone
+		}
+	}
+	return m, nil
+}
+
+// exportScript writes conv as a readable script to the working directory.
+func (m ConversationListModel) exportScript(conv db.Conversation, format string) ConversationListModel {
+	path, err := writeScript(m.db, conv, format)
+	if err != nil {
+		m.logger.LogError("conversation_export", err)
+		return m.setNotice(fmt.Sprintf("Export failed: %v", err), true)
+	}
+	m.logger.Info("conversation_exported", "id", conv.ID, "path", path)
+	return m.setNotice(fmt.Sprintf("Exported to %s", path), false)
+}
+
+func (m ConversationListModel) toggleArchived(conv db.Conversation) ConversationListModel {
+	archive := !conv.ArchivedAt.Valid
+	if err := m.db.SetConversationArchived(conv.ID, archive); err != nil {
+		m.logger.LogError("conversation_archive", err)
+		return m.setNotice(fmt.Sprintf("Archive failed: %v", err), true)
+	}
+	m.logger.Info("conversation_archived", "id", conv.ID, "archived", archive)
+	m = m.reload(conv.ID)
+	if archive {
+		return m.setNotice(fmt.Sprintf("Archived %q", conv.Title), false)
+	}
+	return m.setNotice(fmt.Sprintf("Restored %q", conv.Title), false)
+}
+
+func (m ConversationListModel) toggleStarred(conv db.Conversation) ConversationListModel {
+	star := !conv.StarredAt.Valid
+	if err := m.db.SetConversationStarred(conv.ID, star); err != nil {
+		m.logger.LogError("conversation_star", err)
+		return m.setNotice(fmt.Sprintf("Star failed: %v", err), true)
+	}
+	m.logger.Info("conversation_starred", "id", conv.ID, "starred", star)
+	m = m.reload(conv.ID)
+	if star {
+		return m.setNotice(fmt.Sprintf("Starred %q; its audio won't be pruned", conv.Title), false)
+	}
+	return m.setNotice(fmt.Sprintf("Unstarred %q", conv.Title), false)
+}
+
+func (m ConversationListModel) duplicate(conv db.Conversation) ConversationListModel {
+	copied, err := library.Duplicate(m.db, conv.ID)
+	if err != nil {
+		m.logger.LogError("conversation_duplicate", err)
+		return m.setNotice(fmt.Sprintf("Duplicate failed: %v", err), true)
+	}
+	m.logger.Info("conversation_duplicated", "id", conv.ID, "new_id", copied.ID)
+	return m.reload(copied.ID).setNotice(fmt.Sprintf("Created %q", copied.Title), false)
+}
+
+func writeScript(database *db.DB, conv db.Conversation, format string) (string, error) {
+	path, err := filepath.Abs(script.DefaultFileName(conv, format))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve export path: %w", err)
+	}
+	doc, err := script.Load(database, conv.ID)
+	if err != nil {
+		return "", err
+	}
+	return path, script.WriteFile(doc, path, format, script.Options{})
+}
+
+// updateSearch handles keys while the search box is open; every edit
+// re-runs the query.
+func (m ConversationListModel) updateSearch(msg tea.KeyMsg) (ConversationListModel, tea.Cmd) {
+	switch {
+	case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))):
+		m.logger.Info("conversation_list_quit")
+		return m, tea.Quit
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+		m.searching = false
+		m.searchInput.Blur()
+		m.searchInput.SetValue("")
+		m.results = nil
+		m.resultCursor = 0
+		m.searchErr = nil
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("up", "ctrl+p"))):
+		if m.resultCursor > 0 {
+			m.resultCursor--
+		}
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("down", "ctrl+n"))):
+		if m.resultCursor < len(m.results)-1 {
+			m.resultCursor++
+		}
+		return m, nil
+
+	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+		if len(m.results) == 0 {
+			return m, nil
+		}
+		match := m.results[m.resultCursor]
+		for _, conv := range m.all {
+			if conv.ID == match.ConversationID {
+				m.logger.Info("conversation_search_selected",
+					"id", conv.ID,
+					"message_id", match.ID,
+				)
+				return m, func() tea.Msg {
+					return ConversationSelectedMsg{Conversation: conv, MessageID: match.ID}
+				}
+			}
+		}
+		return m, nil
+	}
+
+	previous := m.searchInput.Value()
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if query := m.searchInput.Value(); query != previous {
+		m.results, m.searchErr = m.db.SearchMessages(query, searchLimit)
+		if m.searchErr != nil {
+			m.logger.LogError("conversation_search", m.searchErr)
+		}
+		m.resultCursor = 0
+	}
+	return m, cmd
+}
+
 func (m ConversationListModel) View() string {
+	if m.searching {
+		return m.searchView()
+	}
+
 	title := styles.TitleStyle.Render("Continue Conversation")
 	subtitle := styles.SubtitleStyle.Render("Select a conversation to continue")
 
@@ -112,7 +457,17 @@ func (m ConversationListModel) View() string {
 	if len(m.conversations) == 0 {
 		emptyMsg := styles.HelpStyle.Render("No conversations found. Create a new one first!")
 		help := styles.HelpStyle.Render("Esc to go back")
-		content := lipgloss.JoinVertical(lipgloss.Left, title, subtitle, "", emptyMsg, "", help)
+		if len(m.all) > 0 {
+			emptyMsg = styles.HelpStyle.Render("Every conversation is archived.")
+			help = styles.HelpStyle.Render("A to show archived | Esc to go back")
+		}
+		var lines []string
+		lines = append(lines, title, subtitle, "", emptyMsg)
+		if m.notice != "" {
+			lines = append(lines, "", m.noticeView())
+		}
+		lines = append(lines, "", help)
+		content := lipgloss.JoinVertical(lipgloss.Left, lines...)
 		box := styles.BoxStyle.Render(content)
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
 	}
@@ -138,9 +493,21 @@ func (m ConversationListModel) View() string {
 			convTitle = "Untitled Conversation"
 		}
 		titleLine := itemTitleStyle.Render(convTitle)
+		if conv.StarredAt.Valid {
+			titleLine = itemTitleStyle.Render("★ " + convTitle)
+		}
+		if i == m.cursor && m.action == listActionRename {
+			titleLine = m.renameInput.View()
+		}
+		if conv.ArchivedAt.Valid {
+			titleLine += " " + timestampStyle.Render("[archived]")
+		}
 
 		// Timestamp in muted color
 		timestamp := conv.CreatedAt.Format("Jan 02, 2006 3:04 PM")
+		if parent := m.parentTitle(conv); parent != "" {
+			timestamp += " · forked from " + truncate(parent, 40)
+		}
 		timestampLine := timestampStyle.Render(timestamp)
 
 		item := fmt.Sprintf("%s%s\n   %s", cursor, titleLine, timestampLine)
@@ -164,14 +531,106 @@ func (m ConversationListModel) View() string {
 	if m.showDetails {
 		detailsHint = "Ctrl+I to hide details"
 	}
-	help := styles.HelpStyle.Render(fmt.Sprintf("↑/↓ or j/k to navigate | Enter to select | %s | Esc to go back", detailsHint))
+	archivedHint := "A to show archived"
+	if m.showArchived {
+		archivedHint = "A to hide archived"
+	}
+	help := styles.HelpStyle.Render(fmt.Sprintf("↑/↓ or j/k to navigate | Enter to select | / to search | %s | Esc to go back", detailsHint))
+	actionsHelp := styles.HelpStyle.Render(fmt.Sprintf("r rename | d delete | s star | a archive/restore | c duplicate | x export | %s", archivedHint))
+
+	lines := []string{title, subtitle, "", items}
+	switch {
+	case m.action == listActionRename:
+		lines = append(lines, m.noticeView(), styles.HelpStyle.Render("Enter to save | Esc to cancel"))
+	case m.action == listActionDelete:
+		conv, _ := m.selected()
+		prompt := lipgloss.NewStyle().Foreground(styles.ErrorColor).Bold(true).
+			Render(fmt.Sprintf("Delete %q with its transcript and audio? This can't be undone. y/n", conv.Title))
+		lines = append(lines, prompt)
+	case m.action == listActionExport:
+		lines = append(lines, m.noticeView(), styles.HelpStyle.Render("Export as: m Markdown | h HTML page | t plain text | Esc to cancel"))
+	default:
+		if m.notice != "" {
+			lines = append(lines, m.noticeView(), "")
+		}
+		lines = append(lines, help, actionsHelp)
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+
+	box := styles.BoxStyle.Render(content)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		box,
+	)
+}
+
+func (m ConversationListModel) noticeView() string {
+	if m.noticeErr {
+		return lipgloss.NewStyle().Foreground(styles.ErrorColor).Render(m.notice)
+	}
+	return lipgloss.NewStyle().Foreground(styles.SecondaryColor).Render(m.notice)
+}
+
+func (m ConversationListModel) searchView() string {
+	title := styles.TitleStyle.Render("Search Conversations")
+	subtitle := styles.SubtitleStyle.Render("Find a message and jump to it")
+
+	titleStylePrimary := lipgloss.NewStyle().Foreground(styles.PrimaryColor).Bold(true)
+	metaStyle := lipgloss.NewStyle().Foreground(styles.MutedColor)
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+	matchStyle := lipgloss.NewStyle().Foreground(styles.PrimaryColor).Bold(true)
+
+	var items string
+	query := strings.TrimSpace(m.searchInput.Value())
+	switch {
+	case m.searchErr != nil:
+		items = styles.HelpStyle.Render(fmt.Sprintf("Search failed: %v", m.searchErr))
+	case query == "":
+		items = styles.HelpStyle.Render("Type to search every conversation's transcript")
+	case len(m.results) == 0:
+		items = styles.HelpStyle.Render("No matching messages")
+	default:
+		// Keep the cursor in a window of searchVisible results.
+		start := max(0, min(m.resultCursor-searchVisible/2, len(m.results)-searchVisible))
+		end := min(start+searchVisible, len(m.results))
+		terms := strings.Fields(query)
+		snippetWidth := max(m.width-24, 30)
+		for i := start; i < end; i++ {
+			match := m.results[i]
+			cursor := "  "
+			itemTitleStyle := titleStylePrimary
+			if i == m.resultCursor {
+				cursor = "> "
+				itemTitleStyle = titleStylePrimary.Underline(true)
+			}
+			convTitle := match.ConversationTitle
+			if convTitle == "" {
+				convTitle = "Untitled Conversation"
+			}
+			meta := metaStyle.Render(fmt.Sprintf("%s · %s", match.Speaker, match.CreatedAt.Format("Jan 02, 2006 3:04 PM")))
+			snippet := strings.Join(strings.Fields(match.Snippet), " ")
+			snippet = highlightTerms(truncate(snippet, snippetWidth), terms, textStyle, matchStyle)
+			items += fmt.Sprintf("%s%s  %s\n   %s\n\n", cursor, itemTitleStyle.Render(convTitle), meta, snippet)
+		}
+		items += metaStyle.Render(fmt.Sprintf("%d of %d", m.resultCursor+1, len(m.results)))
+	}
+
+	help := styles.HelpStyle.Render("Type to search | ↑/↓ to navigate | Enter to open at message | Esc to go back")
 
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
 		subtitle,
 		"",
+		m.searchInput.View(),
+		"",
 		items,
+		"",
 		help,
 	)
 
@@ -186,10 +645,51 @@ func (m ConversationListModel) View() string {
 	)
 }
 
+// highlightTerms renders text with every case-insensitive occurrence of a
+// search term picked out.
+func highlightTerms(text string, terms []string, base, highlight lipgloss.Style) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		return base.Render(text)
+	}
+
+	marked := make([]bool, len(runes))
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				for j := i; j < i+len(needle); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && marked[j] == marked[i] {
+			j++
+		}
+		style := base
+		if marked[i] {
+			style = highlight
+		}
+		b.WriteString(style.Render(string(runes[i:j])))
+		i = j
+	}
+	return b.String()
+}
+
 // truncate shortens a string to maxLen and adds "..." if truncated
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	return string(runes[:maxLen-3]) + "..."
 }
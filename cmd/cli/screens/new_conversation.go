@@ -131,8 +131,8 @@ func (m CreateConversationModel) Update(msg tea.Msg) (CreateConversationModel, t
 					}
 					m.logger.Info("new_conversation_created",
 						"title", m.titleInput.Value(),
-						"topic", m.topicInput.Value(),
-						"persona", m.personaInput.Value(),
+						"topic_length", len(m.topicInput.Value()),
+						"persona_length", len(m.personaInput.Value()),
 						"provider", provider,
 					)
 					return m, func() tea.Msg {
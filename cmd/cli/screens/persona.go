@@ -52,7 +52,7 @@ func (m PersonaModel) Update(msg tea.Msg) (PersonaModel, tea.Cmd) {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
 			if m.textInput.Value() != "" {
 				m.persona = m.textInput.Value()
-				m.logger.Info("persona_entered", "persona", m.persona)
+				m.logger.Info("persona_entered", "persona_length", len(m.persona))
 				return m, func() tea.Msg { return PersonaSelectedMsg{Persona: m.persona} }
 			}
 		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))):
@@ -44,6 +44,10 @@ func getAvailableProviders() []ProviderInfo {
 
 	var providers []ProviderInfo
 	for name, providerCfg := range cfg.Providers {
+		// Speech-only providers (e.g. local whisper.cpp) can't hold a conversation.
+		if providerCfg.InferenceURL == "" {
+			continue
+		}
 		displayName := name
 		if providerCfg.ChatModel != "" {
 			displayName = fmt.Sprintf("%s (%s)", name, providerCfg.ChatModel)
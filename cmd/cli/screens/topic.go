@@ -52,7 +52,7 @@ func (m TopicModel) Update(msg tea.Msg) (TopicModel, tea.Cmd) {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
 			if m.textInput.Value() != "" {
 				m.topic = m.textInput.Value()
-				m.logger.Info("topic_entered", "topic", m.topic)
+				m.logger.Info("topic_entered", "topic_length", len(m.topic))
 				return m, func() tea.Msg { return TopicSelectedMsg{Topic: m.topic} }
 			}
 		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))):
@@ -0,0 +1,257 @@
+package screens
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/logger"
+	"github.com/nraghuveer/vibecast/lib/models"
+	"github.com/nraghuveer/vibecast/lib/storage"
+	"github.com/nraghuveer/vibecast/lib/ttscache"
+)
+
+// Speech blocks are synthesized by up to ai.tts_workers concurrent jobs, but
+// saved and played strictly in the order they were queued: a finished job
+// waits until every job ahead of it has been saved.
+
+// ttsItem is a line waiting to be synthesized
+type ttsItem struct {
+	text      string
+	speaker   models.SpeakerType
+	messageID string // transcript message the line belongs to
+}
+
+// ttsJob is a started synthesis. Jobs are shared by pointer between model
+// copies; only Update touches them.
+type ttsJob struct {
+	seq      int
+	item     ttsItem
+	provider string
+	cancel   context.CancelFunc
+	done     bool
+	data     []byte
+	err      error
+}
+
+// TTSSynthesizedMsg delivers the audio for a started job.
+type TTSSynthesizedMsg struct {
+	Seq  int
+	Data []byte
+	Err  error
+}
+
+// enqueueTTSBlocks queues a guest answer's speech blocks.
+func (m ConversationModel) enqueueTTSBlocks(blocks []string) (ConversationModel, tea.Cmd) {
+	if len(blocks) == 0 {
+		return m, nil
+	}
+	for _, block := range blocks {
+		trimmed := strings.TrimSpace(block)
+		if trimmed == "" {
+			continue
+		}
+		m.ttsQueue = append(m.ttsQueue, ttsItem{text: trimmed, speaker: models.GUEST, messageID: m.answerID})
+	}
+	return m.startNextTTS()
+}
+
+// startNextTTS starts queued items while workers are free.
+func (m ConversationModel) startNextTTS() (ConversationModel, tea.Cmd) {
+	var cmds []tea.Cmd
+	for m.ttsRunning() < config.GetTTSWorkers() && len(m.ttsQueue) > 0 {
+		item := m.ttsQueue[0]
+		item.text = strings.TrimSpace(item.text)
+		m.ttsQueue = m.ttsQueue[1:]
+		if item.text == "" || item == m.ttsLastChunk {
+			continue
+		}
+		m.ttsLastChunk = item
+
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		m.ttsSeq++
+		job := &ttsJob{seq: m.ttsSeq, item: item, provider: m.ttsProvider(item.speaker), cancel: cancel}
+		m.ttsJobs = append(m.ttsJobs, job)
+		cmds = append(cmds, m.synthesizeCmd(ctx, job))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// ttsRunning counts jobs still waiting on the provider.
+func (m ConversationModel) ttsRunning() int {
+	running := 0
+	for _, job := range m.ttsJobs {
+		if !job.done {
+			running++
+		}
+	}
+	return running
+}
+
+// finishTTS records a job's audio, then saves the next job in line.
+func (m ConversationModel) finishTTS(msg TTSSynthesizedMsg) (ConversationModel, tea.Cmd) {
+	for _, job := range m.ttsJobs {
+		if job.seq == msg.Seq {
+			job.cancel()
+			job.done = true
+			job.data = msg.Data
+			job.err = msg.Err
+			break
+		}
+	}
+	return m.advanceTTS()
+}
+
+// advanceTTS saves the head job once it has finished, dropping failed jobs,
+// and starts queued items on any free workers.
+func (m ConversationModel) advanceTTS() (ConversationModel, tea.Cmd) {
+	var saveCmd tea.Cmd
+	for !m.ttsSaving && len(m.ttsJobs) > 0 && m.ttsJobs[0].done {
+		head := m.ttsJobs[0]
+		if head.err != nil {
+			if !errors.Is(head.err, context.Canceled) {
+				m.logger.LogError("tts_synthesize", head.err)
+			}
+			m.ttsJobs = m.ttsJobs[1:]
+			continue
+		}
+		m.ttsSaving = true
+		saveCmd = m.saveTTSCmd(head)
+	}
+
+	m, startCmd := m.startNextTTS()
+	return m, tea.Batch(saveCmd, startCmd)
+}
+
+// savedTTS removes the job that was just saved.
+func (m ConversationModel) savedTTS() ConversationModel {
+	if m.ttsSaving && len(m.ttsJobs) > 0 {
+		m.ttsJobs = m.ttsJobs[1:]
+	}
+	m.ttsSaving = false
+	return m
+}
+
+// cancelGuestTTS drops a superseded answer: queued guest blocks are removed
+// and their running jobs cancelled. Host lines are kept, as is a clip that is
+// already being saved.
+func (m ConversationModel) cancelGuestTTS() ConversationModel {
+	var jobs []*ttsJob
+	for i, job := range m.ttsJobs {
+		if job.item.speaker == models.HOST || (i == 0 && m.ttsSaving) {
+			jobs = append(jobs, job)
+			continue
+		}
+		job.cancel()
+	}
+	m.ttsJobs = jobs
+	m.ttsQueue = hostTTSItems(m.ttsQueue)
+	m.ttsLastChunk = ttsItem{}
+	return m
+}
+
+// cancelAllTTS stops every running job when the conversation ends.
+func (m *ConversationModel) cancelAllTTS() {
+	for _, job := range m.ttsJobs {
+		job.cancel()
+	}
+	m.ttsJobs = nil
+	m.ttsQueue = nil
+	m.ttsSaving = false
+}
+
+func hostTTSItems(items []ttsItem) []ttsItem {
+	var kept []ttsItem
+	for _, item := range items {
+		if item.speaker == models.HOST {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// ttsVoice returns the voice a speaker's lines are read in.
+func (m ConversationModel) ttsVoice(speaker models.SpeakerType) models.VoiceProfile {
+	if speaker == models.HOST {
+		return m.hostVoice
+	}
+	return m.voice
+}
+
+// ttsProvider picks the provider for a speaker's voice, falling back to
+// openai when the configured provider has no TTS endpoint.
+func (m ConversationModel) ttsProvider(speaker models.SpeakerType) string {
+	// Keep TTS best-effort; conversation should work without it.
+	ttsProvider := m.ttsVoice(speaker).Provider
+	if strings.TrimSpace(ttsProvider) == "" {
+		ttsProvider = config.GetTextToSpeechProvider()
+	}
+	if strings.TrimSpace(ttsProvider) == "" {
+		ttsProvider = "openai"
+	}
+
+	if url, err := config.GetProviderTTSURL(ttsProvider); err != nil || strings.TrimSpace(url) == "" {
+		if _, err := config.GetProviderConfig("openai"); err == nil {
+			ttsProvider = "openai"
+		}
+	}
+	return ttsProvider
+}
+
+func (m ConversationModel) synthesizeCmd(ctx context.Context, job *ttsJob) tea.Cmd {
+	voice := m.ttsVoice(job.item.speaker)
+	persona := m.persona
+	topic := m.topic
+	client := m.llmClient
+	seq := job.seq
+	item := job.item
+	ttsProvider := job.provider
+
+	return func() tea.Msg {
+		cleanText := normalizeTTSInput(item.text)
+		cacheKey := ttscache.NewKey(ttsProvider, voice, cleanText)
+		if audioData, ok := ttscache.Get(cacheKey); ok {
+			return TTSSynthesizedMsg{Seq: seq, Data: audioData}
+		}
+
+		var audioData []byte
+		var err error
+		if item.speaker == models.HOST {
+			audioData, err = client.SynthesizeHostSpeech(ctx, ttsProvider, voice, cleanText)
+		} else {
+			audioData, _, err = client.SynthesizeGuestSpeech(ctx, "", ttsProvider, persona, topic, voice, cleanText)
+		}
+		if err != nil {
+			return TTSSynthesizedMsg{Seq: seq, Err: err}
+		}
+		if err := ttscache.Put(cacheKey, audioData); err != nil {
+			logger.GetInstance().LogError("tts_cache_put", err)
+		}
+		return TTSSynthesizedMsg{Seq: seq, Data: audioData}
+	}
+}
+
+func (m ConversationModel) saveTTSCmd(job *ttsJob) tea.Cmd {
+	database := m.db
+	conversationID := m.id
+	speaker := job.item.speaker
+	messageID := job.item.messageID
+	data := job.data
+	format := config.GetProviderTTSFormat(job.provider)
+
+	return func() tea.Msg {
+		file, err := storage.SaveAudio(database, conversationID, messageID, speaker, data, format)
+		if err != nil {
+			return TTSSavedMsg{Filename: file.Filename, Speaker: speaker, MessageID: messageID, Err: err}
+		}
+		audioDir, err := storage.GetAudioDir(conversationID)
+		if err != nil {
+			return TTSSavedMsg{Filename: file.Filename, Speaker: speaker, MessageID: messageID, Err: err}
+		}
+		return TTSSavedMsg{Filename: file.Filename, Path: filepath.Join(audioDir, file.Filename), Speaker: speaker, MessageID: messageID}
+	}
+}
@@ -1,39 +1,87 @@
 package screens
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/nraghuveer/vibecast/cmd/cli/mock"
 	"github.com/nraghuveer/vibecast/cmd/cli/styles"
+	"github.com/nraghuveer/vibecast/lib/db"
 	"github.com/nraghuveer/vibecast/lib/logger"
+	"github.com/nraghuveer/vibecast/lib/models"
+	"github.com/nraghuveer/vibecast/lib/voices"
 )
 
 // VoiceModel represents the voice selection screen
 type VoiceModel struct {
-	voices   []mock.Voice
+	db       *db.DB
+	voices   []models.VoiceProfile
 	cursor   int
-	selected mock.Voice
+	selected models.VoiceProfile
 	width    int
 	height   int
 	logger   *logger.Logger
+	host     bool // picking the host's voice; the first entry is "no host audio"
 }
 
 // NewVoiceModel creates a new voice selection screen model
-func NewVoiceModel() VoiceModel {
-	voices := mock.GetVoices()
+func NewVoiceModel(database *db.DB) VoiceModel {
+	log := logger.GetInstance()
+	available, err := voices.Available(database)
+	if err != nil {
+		log.LogError("voice_list_load", err)
+	}
 	return VoiceModel{
-		voices: voices,
+		db:     database,
+		voices: available,
 		cursor: 0,
-		logger: logger.GetInstance(),
+		logger: log,
+	}
+}
+
+// noHostVoice is the host picker entry that leaves host lines silent.
+var noHostVoice = models.VoiceProfile{Name: "No host audio", Description: "only the guest is voiced"}
+
+// NewHostVoiceModel creates the screen that picks the voice for the host's
+// own lines, starting on the ai.host_voice default.
+func NewHostVoiceModel(database *db.DB) VoiceModel {
+	m := NewVoiceModel(database)
+	m.host = true
+	m.voices = append([]models.VoiceProfile{noHostVoice}, m.voices...)
+	if def, ok := voices.DefaultHost(database); ok {
+		for i, v := range m.voices {
+			if v.ID == def.ID {
+				m.cursor = i
+				break
+			}
+		}
 	}
+	return m
 }
 
-// Init initializes the voice model
+// VoicesLoadedMsg delivers the voice catalog after fetching provider voices
+type VoicesLoadedMsg struct {
+	Voices []models.VoiceProfile
+	Err    error
+}
+
+// Init fetches voices from providers that publish a voice list
 func (m VoiceModel) Init() tea.Cmd {
-	return nil
+	database := m.db
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		refreshErr := voices.Refresh(ctx, database)
+		available, err := voices.Available(database)
+		if err != nil {
+			return VoicesLoadedMsg{Err: err}
+		}
+		return VoicesLoadedMsg{Voices: available, Err: refreshErr}
+	}
 }
 
 // Update handles messages for the voice screen
@@ -42,6 +90,19 @@ func (m VoiceModel) Update(msg tea.Msg) (VoiceModel, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+	case VoicesLoadedMsg:
+		if msg.Err != nil {
+			m.logger.LogError("voice_catalog_refresh", msg.Err)
+		}
+		if len(msg.Voices) > 0 {
+			m.voices = msg.Voices
+			if m.host {
+				m.voices = append([]models.VoiceProfile{noHostVoice}, m.voices...)
+			}
+			if m.cursor >= len(m.voices) {
+				m.cursor = len(m.voices) - 1
+			}
+		}
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
@@ -53,10 +114,18 @@ func (m VoiceModel) Update(msg tea.Msg) (VoiceModel, tea.Cmd) {
 				m.cursor++
 			}
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			if len(m.voices) == 0 {
+				return m, nil
+			}
 			m.selected = m.voices[m.cursor]
+			if m.host {
+				m.logger.Info("host_voice_selected", "id", m.selected.ID, "name", m.selected.Name)
+				return m, func() tea.Msg { return HostVoiceSelectedMsg{Voice: m.selected} }
+			}
 			m.logger.Info("voice_selected",
 				"id", m.selected.ID,
 				"name", m.selected.Name,
+				"provider", m.selected.Provider,
 			)
 			return m, func() tea.Msg { return VoiceSelectedMsg{Voice: m.selected} }
 		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))):
@@ -74,6 +143,12 @@ func (m VoiceModel) View() string {
 	description := styles.SubtitleStyle.Render(
 		"Choose the voice that best fits your guest's persona",
 	)
+	if m.host {
+		title = styles.TitleStyle.Render("Select a voice for your own lines")
+		description = styles.SubtitleStyle.Render(
+			"Your questions are read in this voice in the episode",
+		)
+	}
 
 	var items string
 	for i, voice := range m.voices {
@@ -84,10 +159,19 @@ func (m VoiceModel) View() string {
 			itemStyle = styles.SelectedStyle
 		}
 
-		voiceDesc := styles.VoiceDescStyle.Render(fmt.Sprintf("(%s)", voice.Description))
+		desc := voice.Provider
+		if voice.Provider == "" {
+			desc = voice.Description
+		} else if voice.Description != "" {
+			desc = fmt.Sprintf("%s, %s", voice.Description, voice.Provider)
+		}
+		voiceDesc := styles.VoiceDescStyle.Render(fmt.Sprintf("(%s)", desc))
 		item := fmt.Sprintf("%s%s %s", cursor, itemStyle.Render(voice.Name), voiceDesc)
 		items += item + "\n"
 	}
+	if len(m.voices) == 0 {
+		items = styles.HelpStyle.Render("No voices available. Configure a provider with tts_url or add voices to your config.") + "\n"
+	}
 
 	help := styles.HelpStyle.Render("↑/↓ or j/k to navigate | Enter to select | Ctrl+C to quit")
 
@@ -112,11 +196,17 @@ func (m VoiceModel) View() string {
 }
 
 // SelectedVoice returns the selected voice
-func (m VoiceModel) SelectedVoice() mock.Voice {
+func (m VoiceModel) SelectedVoice() models.VoiceProfile {
 	return m.selected
 }
 
 // VoiceSelectedMsg signals that a voice has been selected
 type VoiceSelectedMsg struct {
-	Voice mock.Voice
+	Voice models.VoiceProfile
+}
+
+// HostVoiceSelectedMsg signals that the host's voice has been selected.
+// A Voice with an empty ID means host lines are not voiced.
+type HostVoiceSelectedMsg struct {
+	Voice models.VoiceProfile
 }
@@ -6,7 +6,9 @@ import (
 )
 
 type WaveModel struct {
-	config WaveConfig
+	config        WaveConfig
+	baseFrequency float64
+	level         float64
 }
 
 type WaveConfig struct {
@@ -22,6 +24,7 @@ func NewWaveModel(phase, frequency, amplitude float64) WaveModel {
 			Frequency: frequency,
 			Amplitude: amplitude,
 		},
+		baseFrequency: frequency,
 	}
 }
 
@@ -33,27 +36,58 @@ func (m *WaveModel) AdvancePhase(delta float64) {
 	m.config.Phase += delta
 }
 
+// SetLevel sets the current audio level (0..1). Louder audio raises both the
+// wave's height and its frequency, up to 3x the base frequency.
+func (m *WaveModel) SetLevel(level float64) {
+	m.level = math.Max(0, math.Min(1, level))
+	m.config.Frequency = m.baseFrequency * (1 + 2*m.level)
+}
+
+// Level returns the current audio level
+func (m *WaveModel) Level() float64 {
+	return m.level
+}
+
+// Height returns the number of lines needed to draw the wave at full amplitude
+func (m *WaveModel) Height() int {
+	return int(math.Ceil(m.config.Amplitude)) + 1
+}
+
+// Render draws the wave with half-block characters, giving two vertical
+// steps per line. Amplitude is the peak displacement in half-lines.
 func (m *WaveModel) Render(width, height int) []string {
-	if width <= 0 {
+	if width <= 0 || height <= 0 {
 		return []string{}
 	}
 
-	var lines []string
-	for y := 0; y < height; y++ {
-		var line strings.Builder
-		for x := 0; x < width; x++ {
-			wave := math.Sin((float64(x)*m.config.Frequency + m.config.Phase + float64(y)*0.1))
-			if math.Abs(wave) > 0.7 {
-				line.WriteString("▀")
-			} else if math.Abs(wave) > 0.3 {
-				line.WriteString("▄")
-			} else if math.Abs(wave) > 0.1 {
-				line.WriteString("░")
-			} else {
-				line.WriteString(" ")
-			}
+	subRows := height * 2
+	center := float64(subRows-1) / 2
+	displacement := math.Min(m.config.Amplitude*m.level, center)
+
+	grid := make([][]rune, height)
+	for y := range grid {
+		grid[y] = []rune(strings.Repeat(" ", width))
+	}
+
+	for x := 0; x < width; x++ {
+		wave := math.Sin(float64(x)*m.config.Frequency + m.config.Phase)
+		sub := int(math.Round(center - displacement*wave))
+		if sub < 0 {
+			sub = 0
+		}
+		if sub >= subRows {
+			sub = subRows - 1
 		}
-		lines = append(lines, line.String())
+		if sub%2 == 0 {
+			grid[sub/2][x] = '▀'
+		} else {
+			grid[sub/2][x] = '▄'
+		}
+	}
+
+	lines := make([]string, height)
+	for y, row := range grid {
+		lines[y] = string(row)
 	}
 	return lines
 }
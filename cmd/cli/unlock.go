@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+)
+
+// Environment variables that supply the encryption secret without a prompt.
+const (
+	passphraseEnv = "VIBECAST_PASSPHRASE"
+	keyfileEnv    = "VIBECAST_KEYFILE"
+)
+
+// unlock loads the data directory's key if it is encrypted.
+func unlock() error {
+	params, err := crypt.LoadParams(config.GetDataDir())
+	if err != nil || params == nil {
+		return err
+	}
+
+	var secret []byte
+	if params.KDF == crypt.KDFKeyfile {
+		path := params.Keyfile
+		if env := os.Getenv(keyfileEnv); env != "" {
+			path = env
+		}
+		if secret, err = readKeyfile(path); err != nil {
+			return err
+		}
+	} else if secret, err = readPassphrase("Passphrase: ", false); err != nil {
+		return err
+	}
+
+	key, err := params.Derive(secret)
+	if err != nil {
+		return err
+	}
+	crypt.SetKey(key)
+	return nil
+}
+
+func readKeyfile(path string) ([]byte, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no keyfile given; set %s", keyfileEnv)
+	}
+	secret, err := os.ReadFile(config.ExpandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyfile (set %s if it moved): %w", keyfileEnv, err)
+	}
+	return secret, nil
+}
+
+// readPassphrase takes the passphrase from VIBECAST_PASSPHRASE, or prompts
+// for it on the terminal, twice when confirm is set.
+func readPassphrase(prompt string, confirm bool) ([]byte, error) {
+	if env := os.Getenv(passphraseEnv); env != "" {
+		return []byte(env), nil
+	}
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return nil, fmt.Errorf("can't ask for a passphrase without a terminal; set %s", passphraseEnv)
+	}
+
+	passphrase, err := promptPassword(prompt)
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase is empty")
+	}
+	if confirm {
+		again, err := promptPassword("Repeat passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, errors.New("passphrases don't match")
+		}
+	}
+	return passphrase, nil
+}
+
+func promptPassword(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return passphrase, nil
+}
@@ -5,7 +5,8 @@
 
 set -e
 
-DEFAULT_DB_PATH="${HOME}/.vibecast/data.sqlite"
+# Follows VIBECAST_HOME like the app; pass a path to match general.data_dir or db_path
+DEFAULT_DB_PATH="${VIBECAST_HOME:-${HOME}/.vibecast}/data.sqlite"
 
 if [ -z "$1" ]; then
     DB_PATH="$DEFAULT_DB_PATH"
@@ -30,6 +31,24 @@ fi
 
 sqlite3 "$DB_PATH" < "$SCHEMA_FILE"
 
+# Apply versioned migrations (schema/v1.sql, schema/v2.sql, ...) not yet recorded in user_version
+CURRENT_VERSION=$(sqlite3 "$DB_PATH" "PRAGMA user_version;")
+VERSION=1
+while [ -f "$SCRIPT_DIR/schema/v$VERSION.sql" ]; do
+    if [ "$VERSION" -gt "$CURRENT_VERSION" ]; then
+        echo "Applying schema v$VERSION"
+        { echo "BEGIN;"; cat "$SCRIPT_DIR/schema/v$VERSION.sql"; echo "PRAGMA user_version = $VERSION;"; echo "COMMIT;"; } | sqlite3 "$DB_PATH"
+    fi
+    VERSION=$((VERSION + 1))
+done
+
+# Full-text search index; needs a sqlite3 built with FTS5
+if sqlite3 "$DB_PATH" < "$SCRIPT_DIR/schema/fts.sql" 2>/dev/null; then
+    echo "Applied search index"
+else
+    echo "FTS5 not available; message search will use substring matching"
+fi
+
 echo "Database created successfully at: $DB_PATH"
 echo ""
 echo "To verify the schema, run:"
@@ -0,0 +1,39 @@
+// Package atomicfile replaces files through a temp file beside them and a
+// rename, so a crash leaves either the old contents or the new ones. The
+// temp file is path + ".tmp"; directory scans skip that suffix.
+package atomicfile
+
+import (
+	"io"
+	"os"
+)
+
+// Write replaces path with data.
+func Write(path string, data []byte, perm os.FileMode) error {
+	return WriteFunc(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// WriteFunc replaces path with what write produces. If write fails the
+// temp file is removed and path is left as it was.
+func WriteFunc(path string, perm os.FileMode, write func(w io.Writer) error) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,421 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nraghuveer/vibecast/lib/config"
+)
+
+// Backend plays single audio files.
+// Start begins playback and returns once the clip is playing; cancelling ctx
+// stops it.
+// Controls reports which of opts Start would apply to a file of format.
+type Backend interface {
+	Name() string
+	Supports(format string) bool
+	Controls(format string, opts PlayOptions) Controls
+	Start(ctx context.Context, path string, opts PlayOptions) (Playback, error)
+}
+
+// PlayOptions adjusts how a clip is played. Backends that can't honor a
+// setting ignore it.
+type PlayOptions struct {
+	Volume float64 // 0..1, where 1 is unchanged
+	Speed  float64 // playback rate, where 1 is unchanged
+}
+
+// DefaultPlayOptions plays clips unchanged.
+var DefaultPlayOptions = PlayOptions{Volume: 1, Speed: 1}
+
+// Controls says which PlayOptions settings a backend applies.
+type Controls struct {
+	Volume bool
+	Speed  bool
+}
+
+// applied counts the settings in opts that differ from the default and that
+// c applies.
+func (c Controls) applied(opts PlayOptions) int {
+	n := 0
+	if c.Volume && opts.Volume != 1 {
+		n++
+	}
+	if c.Speed && opts.Speed != 1 {
+		n++
+	}
+	return n
+}
+
+// Player.SetVolume and SetSpeed return these when the backend can't apply
+// the change.
+var (
+	ErrNoVolume = errors.New("audio player can't change the volume")
+	ErrNoSpeed  = errors.New("audio player can't change the playback speed")
+)
+
+// Playback is a clip that has started playing.
+type Playback interface {
+	// Wait blocks until the clip finishes or is stopped.
+	Wait() error
+	Pause() error
+	Resume() error
+}
+
+// donePlayback is a clip that finished as soon as it started.
+type donePlayback struct {
+	err error
+}
+
+func (p donePlayback) Wait() error   { return p.err }
+func (p donePlayback) Pause() error  { return nil }
+func (p donePlayback) Resume() error { return nil }
+
+// processPlayback is a clip being played by an external process. Pausing
+// suspends the process.
+type processPlayback struct {
+	ctx  context.Context
+	name string
+	cmd  *exec.Cmd
+}
+
+func (p *processPlayback) Wait() error {
+	if err := p.cmd.Wait(); err != nil {
+		if p.ctx.Err() != nil {
+			return p.ctx.Err()
+		}
+		return fmt.Errorf("%s: %w", p.name, err)
+	}
+	return nil
+}
+
+func (p *processPlayback) Pause() error {
+	return suspendProcess(p.cmd.Process)
+}
+
+func (p *processPlayback) Resume() error {
+	return resumeProcess(p.cmd.Process)
+}
+
+// commandBackend plays files by running an external player binary.
+type commandBackend struct {
+	name    string
+	path    string
+	args    []string
+	formats []string // nil means every format
+	volume  func(v float64) []string
+	speed   func(s float64) []string
+}
+
+func (b *commandBackend) Name() string {
+	return b.name
+}
+
+func (b *commandBackend) Supports(format string) bool {
+	if b.formats == nil {
+		return true
+	}
+	for _, f := range b.formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *commandBackend) Controls(format string, opts PlayOptions) Controls {
+	return Controls{Volume: b.volume != nil, Speed: b.speed != nil}
+}
+
+func (b *commandBackend) Start(ctx context.Context, path string, opts PlayOptions) (Playback, error) {
+	args := append([]string{}, b.args...)
+	if b.volume != nil && opts.Volume != 1 {
+		args = append(args, b.volume(opts.Volume)...)
+	}
+	if b.speed != nil && opts.Speed != 1 {
+		args = append(args, b.speed(opts.Speed)...)
+	}
+	args = append(args, path)
+
+	cmd := exec.CommandContext(ctx, b.path, args...)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.name, err)
+	}
+	return &processPlayback{ctx: ctx, name: b.name, cmd: cmd}, nil
+}
+
+// playerSpec describes a known external player and how to pass it volume
+// and speed. A nil volume or speed func means the player can't adjust it.
+type playerSpec struct {
+	binary  string
+	args    []string
+	formats []string
+	volume  func(v float64) []string
+	speed   func(s float64) []string
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// knownPlayers maps audio_player names to their command lines. paplay and
+// pw-play decode through libsndfile (no MP3); aplay only handles WAV.
+var knownPlayers = map[string]playerSpec{
+	"afplay": {
+		binary: "afplay",
+		volume: func(v float64) []string { return []string{"-v", formatFloat(v)} },
+		speed:  func(s float64) []string { return []string{"-r", formatFloat(s), "-q", "1"} },
+	},
+	"paplay": {
+		binary:  "paplay",
+		formats: []string{FormatWAV, FormatFLAC, FormatOpus},
+		volume:  func(v float64) []string { return []string{fmt.Sprintf("--volume=%d", int(v*65536))} },
+	},
+	"pw-play": {
+		binary:  "pw-play",
+		formats: []string{FormatWAV, FormatFLAC, FormatOpus},
+		volume:  func(v float64) []string { return []string{"--volume=" + formatFloat(v)} },
+	},
+	"aplay": {binary: "aplay", args: []string{"-q"}, formats: []string{FormatWAV}},
+	"ffplay": {
+		binary: "ffplay",
+		args:   []string{"-nodisp", "-autoexit", "-loglevel", "quiet"},
+		volume: func(v float64) []string { return []string{"-volume", strconv.Itoa(int(v * 100))} },
+		speed:  func(s float64) []string { return []string{"-af", "atempo=" + formatFloat(s)} },
+	},
+	"mpv": {
+		binary: "mpv",
+		args:   []string{"--no-video", "--really-quiet"},
+		volume: func(v float64) []string { return []string{fmt.Sprintf("--volume=%d", int(v*100))} },
+		speed:  func(s float64) []string { return []string{"--speed=" + formatFloat(s)} },
+	},
+}
+
+// detectionOrder is the preference order for auto-detection on each OS.
+func detectionOrder() []string {
+	if runtime.GOOS == "darwin" {
+		return []string{"afplay", "ffplay", "mpv"}
+	}
+	return []string{"pw-play", "paplay", "ffplay", "mpv", "aplay"}
+}
+
+func newCommandBackend(name string) (*commandBackend, error) {
+	spec, ok := knownPlayers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown audio player %q", name)
+	}
+	path, err := exec.LookPath(spec.binary)
+	if err != nil {
+		return nil, err
+	}
+	return &commandBackend{
+		name:    name,
+		path:    path,
+		args:    spec.args,
+		formats: spec.formats,
+		volume:  spec.volume,
+		speed:   spec.speed,
+	}, nil
+}
+
+// chainBackend hands each file to the first backend that supports its format.
+type chainBackend struct {
+	backends []Backend
+}
+
+func (c *chainBackend) Name() string {
+	names := make([]string, 0, len(c.backends))
+	for _, b := range c.backends {
+		names = append(names, b.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (c *chainBackend) Supports(format string) bool {
+	for _, b := range c.backends {
+		if b.Supports(format) {
+			return true
+		}
+	}
+	return false
+}
+
+// pick returns the backend that plays format applying the most of opts,
+// preferring earlier backends, or nil when none supports format.
+func (c *chainBackend) pick(format string, opts PlayOptions) Backend {
+	var best Backend
+	bestApplied := -1
+	for _, b := range c.backends {
+		if !b.Supports(format) {
+			continue
+		}
+		if n := b.Controls(format, opts).applied(opts); n > bestApplied {
+			best, bestApplied = b, n
+		}
+	}
+	return best
+}
+
+func (c *chainBackend) Controls(format string, opts PlayOptions) Controls {
+	if b := c.pick(format, opts); b != nil {
+		return b.Controls(format, opts)
+	}
+	return Controls{}
+}
+
+func (c *chainBackend) Start(ctx context.Context, path string, opts PlayOptions) (Playback, error) {
+	format := fileFormat(path)
+	if b := c.pick(format, opts); b != nil {
+		return b.Start(ctx, path, opts)
+	}
+	return nil, fmt.Errorf("no audio player supports %s files", format)
+}
+
+// DetectBackend returns every installed player, in preference order, chained
+// so that formats the preferred player can't decode, or volume and speed
+// settings it can't apply, fall through to the next.
+func DetectBackend() (Backend, error) {
+	var found []Backend
+	for _, name := range detectionOrder() {
+		if b, err := newCommandBackend(name); err == nil {
+			found = append(found, b)
+		}
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no audio player found (tried %s)", strings.Join(detectionOrder(), ", "))
+	}
+	if len(found) == 1 {
+		return found[0], nil
+	}
+	return &chainBackend{backends: found}, nil
+}
+
+// NewBackend resolves a ui.audio_player setting:
+//
+//	"" or "auto"     detect an installed player
+//	"none" / "null"  discard audio
+//	"file:<dir>"     copy every played clip into dir (~ and $VARS expanded)
+//	<name>           one of afplay, paplay, pw-play, aplay, ffplay, mpv
+func NewBackend(setting string) (Backend, error) {
+	setting = strings.TrimSpace(setting)
+	switch {
+	case setting == "" || setting == "auto":
+		return DetectBackend()
+	case setting == "none" || setting == "null":
+		return NewNullBackend(), nil
+	case strings.HasPrefix(setting, "file:"):
+		return NewFileSinkBackend(config.ExpandPath(strings.TrimPrefix(setting, "file:")))
+	}
+	return newCommandBackend(setting)
+}
+
+// NullBackend accepts every clip and discards it immediately.
+type NullBackend struct {
+	mu     sync.Mutex
+	played []string
+}
+
+func NewNullBackend() *NullBackend {
+	return &NullBackend{}
+}
+
+func (b *NullBackend) Name() string {
+	return "null"
+}
+
+func (b *NullBackend) Supports(format string) bool {
+	return true
+}
+
+func (b *NullBackend) Controls(format string, opts PlayOptions) Controls {
+	return Controls{}
+}
+
+func (b *NullBackend) Start(ctx context.Context, path string, opts PlayOptions) (Playback, error) {
+	b.mu.Lock()
+	b.played = append(b.played, path)
+	b.mu.Unlock()
+	return donePlayback{err: ctx.Err()}, nil
+}
+
+// Played returns the paths handed to the backend, in order.
+func (b *NullBackend) Played() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string{}, b.played...)
+}
+
+// FileSinkBackend copies every played clip into a directory, prefixed with a
+// sequence number so the playback order can be checked afterwards.
+type FileSinkBackend struct {
+	dir string
+	mu  sync.Mutex
+	seq int
+}
+
+func NewFileSinkBackend(dir string) (*FileSinkBackend, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, errors.New("file sink directory not set")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audio sink directory: %w", err)
+	}
+	return &FileSinkBackend{dir: dir}, nil
+}
+
+func (b *FileSinkBackend) Name() string {
+	return "file:" + b.dir
+}
+
+func (b *FileSinkBackend) Supports(format string) bool {
+	return true
+}
+
+func (b *FileSinkBackend) Controls(format string, opts PlayOptions) Controls {
+	return Controls{}
+}
+
+func (b *FileSinkBackend) Start(ctx context.Context, path string, opts PlayOptions) (Playback, error) {
+	return donePlayback{err: b.copy(ctx, path)}, nil
+}
+
+func (b *FileSinkBackend) copy(ctx context.Context, path string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.seq++
+	dest := filepath.Join(b.dir, fmt.Sprintf("%04d_%s", b.seq, filepath.Base(path)))
+	b.mu.Unlock()
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// fileFormat returns the audio format implied by a file's extension.
+func fileFormat(path string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+}
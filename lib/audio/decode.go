@@ -0,0 +1,88 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+
+	"github.com/nraghuveer/vibecast/lib/crypt"
+)
+
+// ErrFFmpegMissing is returned when a compressed clip needs ffmpeg to decode
+// and it isn't on PATH.
+var ErrFFmpegMissing = errors.New("ffmpeg not found on PATH")
+
+// FFmpegPath returns the path of the ffmpeg binary, or "" when it isn't installed.
+func FFmpegPath() string {
+	path, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// DecodeFile decodes an audio file to mono PCM at sampleRate (0 keeps the
+// file's own rate where it is known). WAV is decoded in Go; other formats
+// go through ffmpeg. Encrypted files are decrypted first.
+func DecodeFile(ctx context.Context, path string, sampleRate int) (*PCM, error) {
+	data, err := crypt.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio file: %w", err)
+	}
+
+	if DetectFormat(data) == FormatWAV {
+		pcm, err := DecodeWAV(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		return Resample(pcm, sampleRate), nil
+	}
+
+	if sampleRate == 0 {
+		if info, err := Probe(data); err == nil && info.SampleRate > 0 {
+			sampleRate = info.SampleRate
+		} else {
+			sampleRate = 48000
+		}
+	}
+	source, cleanup, err := crypt.PlainFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio file: %w", err)
+	}
+	defer cleanup()
+	return decodeWithFFmpeg(ctx, source, sampleRate)
+}
+
+func decodeWithFFmpeg(ctx context.Context, path string, sampleRate int) (*PCM, error) {
+	ffmpeg := FFmpegPath()
+	if ffmpeg == "" {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, ErrFFmpegMissing)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpeg,
+		"-v", "error",
+		"-i", path,
+		"-f", "f32le",
+		"-ac", "1",
+		"-ar", strconv.Itoa(sampleRate),
+		"-",
+	)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed to decode %s: %w: %s", path, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	raw := stdout.Bytes()
+	samples := make([]float32, len(raw)/4)
+	for i := range samples {
+		samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:]))
+	}
+	return &PCM{SampleRate: sampleRate, Samples: samples}, nil
+}
@@ -0,0 +1,72 @@
+package audio
+
+import (
+	"math"
+	"os"
+	"time"
+)
+
+// MeterWindow is the length of audio summarized by one level reading.
+const MeterWindow = 50 * time.Millisecond
+
+// Level is a loudness reading for the clip currently playing.
+// RMS is normalized to the loudest window of the clip, so it ranges 0..1.
+type Level struct {
+	Path    string
+	RMS     float64
+	Playing bool
+}
+
+// RMSLevels computes the RMS of each window of samples, normalized so the
+// loudest window is 1.
+func RMSLevels(pcm *PCM, window time.Duration) []float64 {
+	if pcm == nil || pcm.SampleRate == 0 || len(pcm.Samples) == 0 {
+		return nil
+	}
+	size := int(int64(pcm.SampleRate) * int64(window) / int64(time.Second))
+	if size <= 0 {
+		size = 1
+	}
+
+	levels := make([]float64, 0, len(pcm.Samples)/size+1)
+	peak := 0.0
+	for start := 0; start < len(pcm.Samples); start += size {
+		end := start + size
+		if end > len(pcm.Samples) {
+			end = len(pcm.Samples)
+		}
+		var sum float64
+		for _, s := range pcm.Samples[start:end] {
+			sum += float64(s) * float64(s)
+		}
+		rms := math.Sqrt(sum / float64(end-start))
+		if rms > peak {
+			peak = rms
+		}
+		levels = append(levels, rms)
+	}
+
+	if peak > 0 {
+		for i := range levels {
+			levels[i] /= peak
+		}
+	}
+	return levels
+}
+
+// meterFile computes the level envelope of a clip on disk. Clips that can't
+// be decoded in pure Go (compressed formats) return nil.
+func meterFile(path string) []float64 {
+	if fileFormat(path) != FormatWAV {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	pcm, err := DecodeWAV(data)
+	if err != nil {
+		return nil
+	}
+	return RMSLevels(pcm, MeterWindow)
+}
@@ -1,71 +1,150 @@
 package audio
 
 import (
+	"context"
 	"errors"
 	"log"
-	"os/exec"
+	"math"
+	"os"
 	"sync"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+)
+
+const (
+	queueSize   = 64
+	levelsSize  = 8
+	historySize = 32
+)
+
+// Volume and speed limits. The speed range is what every speed-capable
+// backend accepts without chaining filters.
+const (
+	MinVolume = 0.0
+	MaxVolume = 1.0
+	MinSpeed  = 0.5
+	MaxSpeed  = 2.0
 )
 
-const queueSize = 64
+// fallbackLevel is published for clips whose format can't be metered, so the
+// UI still shows that something is playing.
+const fallbackLevel = 0.5
 
 type Player struct {
 	queue   chan string
+	levels  chan Level
 	mu      sync.Mutex
 	cond    *sync.Cond
 	pending int
+	backend Backend
+
+	opts     PlayOptions
+	format   string // format of the latest clip, for SetVolume and SetSpeed
+	paused   bool
+	current  string
+	playback Playback
+	cancel   context.CancelFunc
+	history  []string
+}
+
+// PlayerState is a snapshot of the player for display.
+type PlayerState struct {
+	Backend string
+	Current string // clip playing (or paused), "" when idle
+	Paused  bool
+	Queued  int // clips waiting after the current one
+	Volume  float64
+	Speed   float64
 }
 
 var (
 	playerOnce sync.Once
 	player     *Player
-	afplayPath string
-	afplayErr  error
+	backendErr error
 )
 
 // Start initializes the background audio player.
-// On macOS it uses afplay. If unavailable, audio is disabled.
+// The backend comes from ui.audio_player, auto-detecting an installed player
+// by default. If none is available, audio is disabled.
 func Start() *Player {
 	playerOnce.Do(func() {
-		path, err := exec.LookPath("afplay")
+		backend, err := NewBackend(config.GetUIConfig().AudioPlayer)
 		if err != nil {
-			afplayErr = err
+			backendErr = err
 			log.Printf("audio disabled: %v", err)
 		} else {
-			afplayPath = path
+			log.Printf("audio backend: %s", backend.Name())
+		}
+		player = &Player{
+			queue:   make(chan string, queueSize),
+			levels:  make(chan Level, levelsSize),
+			backend: backend,
+			opts:    DefaultPlayOptions,
+			format:  FormatWAV,
 		}
-		player = &Player{queue: make(chan string, queueSize)}
 		player.cond = sync.NewCond(&player.mu)
 		go player.loop()
 	})
 	return player
 }
 
+// BackendName returns the active playback backend, or "" when audio is disabled.
+func BackendName() string {
+	Start()
+	if player.backend == nil {
+		return ""
+	}
+	return player.backend.Name()
+}
+
+// Levels returns the loudness readings published every MeterWindow while a
+// clip plays, followed by a reading with Playing=false when it ends.
+// Readings are dropped when nobody is receiving.
+func Levels() <-chan Level {
+	Start()
+	return player.levels
+}
+
 // Enqueue schedules an audio file for playback.
 // It is non-blocking; when the queue is full it returns an error.
 func Enqueue(path string) error {
 	Start()
-	if afplayErr != nil {
-		return afplayErr
+	if backendErr != nil {
+		return backendErr
 	}
-	player.mu.Lock()
-	player.pending++
-	player.mu.Unlock()
+	return player.enqueue(path)
+}
+
+func (p *Player) enqueue(path string) error {
+	p.mu.Lock()
+	p.pending++
+	p.mu.Unlock()
 	select {
-	case player.queue <- path:
+	case p.queue <- path:
 		return nil
 	default:
-		player.decrementPending()
+		p.decrementPending()
 		return errors.New("audio queue full")
 	}
 }
 
 // Drain blocks until all queued audio has finished playing.
+// Paused audio is discarded rather than waited for.
 func Drain() {
 	Start()
-	if afplayErr != nil {
+	if backendErr != nil {
+		return
+	}
+	player.mu.Lock()
+	paused := player.paused
+	player.mu.Unlock()
+	if paused {
+		player.Clear()
 		return
 	}
+
 	player.mu.Lock()
 	for player.pending > 0 {
 		player.cond.Wait()
@@ -73,20 +152,303 @@ func Drain() {
 	player.mu.Unlock()
 }
 
+// State returns a snapshot of the player.
+func (p *Player) State() PlayerState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state := PlayerState{
+		Current: p.current,
+		Paused:  p.paused,
+		Queued:  p.pending,
+		Volume:  p.opts.Volume,
+		Speed:   p.opts.Speed,
+	}
+	if p.current != "" && state.Queued > 0 {
+		state.Queued--
+	}
+	if p.backend != nil {
+		state.Backend = p.backend.Name()
+	}
+	return state
+}
+
+// Pause suspends the current clip and holds the queue until Resume.
+func (p *Player) Pause() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused {
+		return nil
+	}
+	if p.playback != nil {
+		if err := p.playback.Pause(); err != nil {
+			return err
+		}
+	}
+	p.paused = true
+	return nil
+}
+
+// Resume continues the current clip and the queue after Pause.
+func (p *Player) Resume() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.paused {
+		return nil
+	}
+	if p.playback != nil {
+		if err := p.playback.Resume(); err != nil {
+			return err
+		}
+	}
+	p.paused = false
+	p.cond.Broadcast()
+	return nil
+}
+
+// TogglePause pauses a playing player and resumes a paused one.
+func (p *Player) TogglePause() error {
+	p.mu.Lock()
+	paused := p.paused
+	p.mu.Unlock()
+	if paused {
+		return p.Resume()
+	}
+	return p.Pause()
+}
+
+// Skip stops the current clip; the next queued clip starts straight away
+// unless the player is paused.
+func (p *Player) Skip() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		p.cancel()
+	}
+}
+
+// Clear drops every queued clip and stops the current one.
+func (p *Player) Clear() {
+	for drained := false; !drained; {
+		select {
+		case <-p.queue:
+			p.decrementPending()
+		default:
+			drained = true
+		}
+	}
+	p.Skip()
+}
+
+// Replay queues the last n clips that were played, oldest first, and
+// returns how many were queued. Clips deleted since they played, e.g. by a
+// rewind, are skipped.
+func (p *Player) Replay(n int) (int, error) {
+	p.mu.Lock()
+	if n < 0 {
+		n = 0
+	}
+	if n > len(p.history) {
+		n = len(p.history)
+	}
+	paths := append([]string{}, p.history[len(p.history)-n:]...)
+	p.mu.Unlock()
+
+	if p.backend == nil {
+		return 0, backendErr
+	}
+	queued := 0
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if err := p.enqueue(path); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// SetVolume sets the volume for clips started from now on, clamped to
+// MinVolume..MaxVolume, and returns the volume in effect. A change the
+// backend can't apply is refused with ErrNoVolume.
+func (p *Player) SetVolume(v float64) (float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	opts := p.opts
+	opts.Volume = math.Max(MinVolume, math.Min(MaxVolume, v))
+	if opts.Volume != 1 && !p.controls(opts).Volume {
+		return p.opts.Volume, ErrNoVolume
+	}
+	p.opts = opts
+	return p.opts.Volume, nil
+}
+
+// SetSpeed sets the playback rate for clips started from now on, clamped to
+// MinSpeed..MaxSpeed, and returns the rate in effect. A change the backend
+// can't apply is refused with ErrNoSpeed.
+func (p *Player) SetSpeed(s float64) (float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	opts := p.opts
+	opts.Speed = math.Max(MinSpeed, math.Min(MaxSpeed, s))
+	if opts.Speed != 1 && !p.controls(opts).Speed {
+		return p.opts.Speed, ErrNoSpeed
+	}
+	p.opts = opts
+	return p.opts.Speed, nil
+}
+
+// controls reports which of opts the backend would apply to the next clip,
+// taken to be in the latest clip's format. The caller holds p.mu.
+func (p *Player) controls(opts PlayOptions) Controls {
+	if p.backend == nil {
+		return Controls{}
+	}
+	return p.backend.Controls(p.format, opts)
+}
+
 func (p *Player) loop() {
 	for path := range p.queue {
-		if path == "" || afplayPath == "" {
+		if path == "" || p.backend == nil {
 			p.decrementPending()
 			continue
 		}
-		cmd := exec.Command(afplayPath, path)
-		if err := cmd.Run(); err != nil {
-			log.Printf("audio playback failed: %v", err)
-		}
+		p.play(path)
 		p.decrementPending()
 	}
 }
 
+// begin waits out a pause, then starts path, read from source, under a
+// context Skip can cancel.
+func (p *Player) begin(path, source string) (Playback, PlayOptions, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.paused {
+		p.cond.Wait()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	playback, err := p.backend.Start(ctx, source, p.opts)
+	if err != nil {
+		cancel()
+		return nil, p.opts, err
+	}
+	p.current = path
+	p.format = fileFormat(path)
+	p.playback = playback
+	p.cancel = cancel
+	p.history = append(p.history, path)
+	if len(p.history) > historySize {
+		p.history = p.history[len(p.history)-historySize:]
+	}
+	return playback, p.opts, nil
+}
+
+func (p *Player) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.current = ""
+	p.playback = nil
+	p.cancel = nil
+}
+
+func (p *Player) isPaused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+func (p *Player) play(path string) {
+	// Players read the file themselves, so encrypted clips are played from
+	// a decrypted temp copy.
+	source, cleanup, err := crypt.PlainFile(path)
+	if err != nil {
+		log.Printf("audio playback failed: %v", err)
+		return
+	}
+	defer cleanup()
+
+	playback, opts, err := p.begin(path, source)
+	if err != nil {
+		log.Printf("audio playback failed: %v", err)
+		return
+	}
+
+	// The meter only runs fast when the backend really sped the clip up.
+	speed := 1.0
+	if p.backend.Controls(fileFormat(path), opts).Speed {
+		speed = opts.Speed
+	}
+	done := make(chan struct{})
+	go p.publishLevels(path, meterFile(source), speed, done)
+
+	if err := playback.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+		log.Printf("audio playback failed: %v", err)
+	}
+
+	close(done)
+	p.finish()
+
+	// Drop the oldest reading if needed so the UI never misses the end of a clip.
+	end := Level{Path: path}
+	select {
+	case p.levels <- end:
+	default:
+		select {
+		case <-p.levels:
+		default:
+		}
+		select {
+		case p.levels <- end:
+		default:
+		}
+	}
+}
+
+// publishLevels walks the clip's level envelope in step with playback,
+// holding its place while paused.
+func (p *Player) publishLevels(path string, envelope []float64, speed float64, done <-chan struct{}) {
+	if speed <= 0 {
+		speed = 1
+	}
+	ticker := time.NewTicker(time.Duration(float64(MeterWindow) / speed))
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		if p.isPaused() {
+			i--
+			select {
+			case p.levels <- Level{Path: path, Playing: true}:
+			default:
+			}
+			continue
+		}
+
+		rms := fallbackLevel
+		if envelope != nil {
+			rms = 0
+			if i < len(envelope) {
+				rms = envelope[i]
+			}
+		}
+
+		select {
+		case p.levels <- Level{Path: path, RMS: rms, Playing: true}:
+		default:
+		}
+	}
+}
+
 func (p *Player) decrementPending() {
 	p.mu.Lock()
 	if p.pending > 0 {
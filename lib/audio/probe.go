@@ -0,0 +1,250 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Supported audio container formats.
+const (
+	FormatWAV  = "wav"
+	FormatMP3  = "mp3"
+	FormatOpus = "opus"
+	FormatFLAC = "flac"
+)
+
+// Info describes an encoded audio clip.
+// Duration and SampleRate are zero when they can't be determined from the
+// header alone.
+type Info struct {
+	Format     string
+	Duration   time.Duration
+	SampleRate int
+	Channels   int
+}
+
+// DetectFormat identifies the container format from the leading bytes.
+// It returns an empty string for unrecognized data.
+func DetectFormat(data []byte) string {
+	switch {
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return FormatWAV
+	case len(data) >= 4 && string(data[0:4]) == "fLaC":
+		return FormatFLAC
+	case len(data) >= 4 && string(data[0:4]) == "OggS":
+		return FormatOpus
+	case len(data) >= 3 && string(data[0:3]) == "ID3":
+		return FormatMP3
+	case len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0:
+		return FormatMP3
+	}
+	return ""
+}
+
+// Probe reads format, duration and sample rate from an encoded clip.
+// MP3 durations are estimated from the first frame's bitrate.
+func Probe(data []byte) (Info, error) {
+	switch DetectFormat(data) {
+	case FormatWAV:
+		return probeWAV(data)
+	case FormatFLAC:
+		return probeFLAC(data)
+	case FormatOpus:
+		return probeOpus(data)
+	case FormatMP3:
+		return probeMP3(data)
+	}
+	return Info{}, errors.New("unrecognized audio format")
+}
+
+// Check reports whether an encoded clip looks complete: its header must
+// parse, and a WAV's data chunk must be as long as the header says. WAVs
+// streamed without a data size pass.
+func Check(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty file")
+	}
+	if _, err := Probe(data); err != nil {
+		return err
+	}
+	if DetectFormat(data) == FormatWAV {
+		header, body, err := parseWAVHeader(data)
+		if err != nil {
+			return err
+		}
+		if header.Truncated {
+			return fmt.Errorf("truncated WAV: %d bytes of audio data, header expects more", len(data)-body)
+		}
+	}
+	return nil
+}
+
+func probeWAV(data []byte) (Info, error) {
+	header, _, err := parseWAVHeader(data)
+	if err != nil {
+		return Info{Format: FormatWAV}, err
+	}
+	if header.SampleRate <= 0 {
+		return Info{Format: FormatWAV}, fmt.Errorf("invalid WAV sample rate %d", header.SampleRate)
+	}
+	return Info{
+		Format:     FormatWAV,
+		Duration:   header.Duration(),
+		SampleRate: header.SampleRate,
+		Channels:   header.Channels,
+	}, nil
+}
+
+// wavHeader holds the fmt chunk fields plus the size of the data chunk.
+type wavHeader struct {
+	AudioFormat   int
+	Channels      int
+	SampleRate    int
+	BitsPerSample int
+	DataSize      int
+	Truncated     bool // the data chunk is shorter than its header says
+}
+
+// Duration computes the playback length of the data chunk.
+func (h wavHeader) Duration() time.Duration {
+	bytesPerSecond := h.SampleRate * h.Channels * h.BitsPerSample / 8
+	if bytesPerSecond == 0 {
+		return 0
+	}
+	return time.Duration(int64(h.DataSize) * int64(time.Second) / int64(bytesPerSecond))
+}
+
+// parseWAVHeader walks the RIFF chunks and returns the header and the offset
+// of the PCM data. Streaming TTS responses often leave the RIFF and data
+// sizes unset (0 or 0xFFFFFFFF); in that case the data runs to end of file.
+func parseWAVHeader(data []byte) (wavHeader, int, error) {
+	var h wavHeader
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return h, 0, errors.New("not a WAV file")
+	}
+
+	haveFmt := false
+	offset := 12
+	for offset+8 <= len(data) {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+
+		switch id {
+		case "fmt ":
+			if body+16 > len(data) {
+				return h, 0, errors.New("truncated WAV fmt chunk")
+			}
+			h.AudioFormat = int(binary.LittleEndian.Uint16(data[body : body+2]))
+			h.Channels = int(binary.LittleEndian.Uint16(data[body+2 : body+4]))
+			h.SampleRate = int(binary.LittleEndian.Uint32(data[body+4 : body+8]))
+			h.BitsPerSample = int(binary.LittleEndian.Uint16(data[body+14 : body+16]))
+			haveFmt = true
+		case "data":
+			if !haveFmt {
+				return h, 0, errors.New("WAV data chunk before fmt chunk")
+			}
+			if size == 0 || size == 0xFFFFFFFF || body+size > len(data) {
+				h.Truncated = size != 0 && size != 0xFFFFFFFF
+				size = len(data) - body
+			}
+			h.DataSize = size
+			return h, body, nil
+		}
+
+		offset = body + size + size%2
+	}
+
+	return h, 0, errors.New("WAV data chunk not found")
+}
+
+func probeFLAC(data []byte) (Info, error) {
+	// STREAMINFO is always the first metadata block: 4-byte marker,
+	// 4-byte block header, then 34 bytes of stream info.
+	if len(data) < 8+18 {
+		return Info{Format: FormatFLAC}, errors.New("truncated FLAC header")
+	}
+	info := data[8:]
+	sampleRate := int(info[10])<<12 | int(info[11])<<4 | int(info[12])>>4
+	channels := int(info[12]>>1&0x07) + 1
+	totalSamples := int64(info[13]&0x0F)<<32 | int64(binary.BigEndian.Uint32(info[14:18]))
+
+	result := Info{Format: FormatFLAC, SampleRate: sampleRate, Channels: channels}
+	if sampleRate > 0 {
+		result.Duration = time.Duration(totalSamples * int64(time.Second) / int64(sampleRate))
+	}
+	return result, nil
+}
+
+func probeOpus(data []byte) (Info, error) {
+	// Ogg Opus always decodes at 48 kHz. The OpusHead packet in the first
+	// page carries the channel count and pre-skip; the granule position of
+	// the last page is the total sample count.
+	const opusRate = 48000
+	result := Info{Format: FormatOpus, SampleRate: opusRate}
+
+	head := bytes.Index(data, []byte("OpusHead"))
+	if head == -1 || head+12 > len(data) {
+		return result, errors.New("OpusHead not found")
+	}
+	result.Channels = int(data[head+9])
+	preSkip := int64(binary.LittleEndian.Uint16(data[head+10 : head+12]))
+
+	last := bytes.LastIndex(data, []byte("OggS"))
+	if last == -1 || last+14 > len(data) {
+		return result, errors.New("truncated Ogg stream")
+	}
+	granule := int64(binary.LittleEndian.Uint64(data[last+6 : last+14]))
+	if granule > preSkip {
+		result.Duration = time.Duration((granule - preSkip) * int64(time.Second) / opusRate)
+	}
+	return result, nil
+}
+
+// Layer III bitrate tables (kbit/s) for MPEG-1 and MPEG-2/2.5.
+var mp3Bitrates = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+var mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+var mp3SampleRates = [4]int{44100, 48000, 32000, 0}
+
+func probeMP3(data []byte) (Info, error) {
+	result := Info{Format: FormatMP3}
+
+	offset := 0
+	if len(data) >= 10 && string(data[0:3]) == "ID3" {
+		// ID3v2 size is a 28-bit syncsafe integer.
+		size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		offset = 10 + size
+	}
+	for offset+4 <= len(data) && !(data[offset] == 0xFF && data[offset+1]&0xE0 == 0xE0) {
+		offset++
+	}
+	if offset+4 > len(data) {
+		return result, errors.New("MP3 frame not found")
+	}
+
+	header := data[offset : offset+4]
+	version := header[1] >> 3 & 0x03
+	bitrate := mp3Bitrates[header[2]>>4] * 1000
+	sampleRate := mp3SampleRates[header[2]>>2&0x03]
+	switch version {
+	case 2: // MPEG-2
+		sampleRate /= 2
+		bitrate = mp3BitratesV2[header[2]>>4] * 1000
+	case 0: // MPEG-2.5
+		sampleRate /= 4
+		bitrate = mp3BitratesV2[header[2]>>4] * 1000
+	}
+	result.SampleRate = sampleRate
+	result.Channels = 2
+	if header[3]>>6 == 3 {
+		result.Channels = 1
+	}
+	if bitrate > 0 {
+		audioBytes := int64(len(data) - offset)
+		result.Duration = time.Duration(audioBytes * 8 * int64(time.Second) / int64(bitrate))
+	}
+	return result, nil
+}
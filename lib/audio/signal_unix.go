@@ -0,0 +1,16 @@
+//go:build !windows
+
+package audio
+
+import (
+	"os"
+	"syscall"
+)
+
+func suspendProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGSTOP)
+}
+
+func resumeProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGCONT)
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package audio
+
+import (
+	"errors"
+	"os"
+)
+
+var errPauseUnsupported = errors.New("pausing playback is not supported on windows")
+
+func suspendProcess(p *os.Process) error {
+	return errPauseUnsupported
+}
+
+func resumeProcess(p *os.Process) error {
+	return errPauseUnsupported
+}
@@ -0,0 +1,216 @@
+package audio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// PCM is decoded audio downmixed to mono, with samples in [-1, 1].
+type PCM struct {
+	SampleRate int
+	Samples    []float32
+}
+
+// Duration returns the playback length of the samples.
+func (p *PCM) Duration() time.Duration {
+	if p.SampleRate == 0 {
+		return 0
+	}
+	return time.Duration(int64(len(p.Samples)) * int64(time.Second) / int64(p.SampleRate))
+}
+
+// DecodeWAV decodes integer (8/16/24/32-bit) or float (32/64-bit) WAV data
+// and downmixes it to mono.
+func DecodeWAV(data []byte) (*PCM, error) {
+	header, offset, err := parseWAVHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	format := header.AudioFormat
+	if format == wavFormatExtensible {
+		// The subformat GUID starts with the real format tag; treat float
+		// and PCM the same way as their plain counterparts.
+		if isFloatExtensible(data) {
+			format = wavFormatFloat
+		} else {
+			format = wavFormatPCM
+		}
+	}
+	if format != wavFormatPCM && format != wavFormatFloat {
+		return nil, fmt.Errorf("unsupported WAV encoding %d", header.AudioFormat)
+	}
+	if header.Channels <= 0 || header.BitsPerSample <= 0 {
+		return nil, fmt.Errorf("invalid WAV header")
+	}
+	if header.SampleRate <= 0 {
+		return nil, fmt.Errorf("invalid WAV sample rate %d", header.SampleRate)
+	}
+	switch {
+	case format == wavFormatFloat && header.BitsPerSample != 32 && header.BitsPerSample != 64:
+		return nil, fmt.Errorf("unsupported %d-bit float WAV", header.BitsPerSample)
+	case format == wavFormatPCM && header.BitsPerSample != 8 && header.BitsPerSample != 16 &&
+		header.BitsPerSample != 24 && header.BitsPerSample != 32:
+		return nil, fmt.Errorf("unsupported %d-bit WAV", header.BitsPerSample)
+	}
+
+	bytesPerSample := header.BitsPerSample / 8
+	frameSize := bytesPerSample * header.Channels
+	if frameSize == 0 {
+		return nil, fmt.Errorf("invalid WAV header")
+	}
+
+	pcmData := data[offset : offset+header.DataSize]
+	frames := len(pcmData) / frameSize
+	samples := make([]float32, frames)
+
+	for i := 0; i < frames; i++ {
+		var sum float64
+		for ch := 0; ch < header.Channels; ch++ {
+			at := i*frameSize + ch*bytesPerSample
+			sum += decodeSample(pcmData[at:at+bytesPerSample], format, header.BitsPerSample)
+		}
+		samples[i] = float32(sum / float64(header.Channels))
+	}
+
+	return &PCM{SampleRate: header.SampleRate, Samples: samples}, nil
+}
+
+func decodeSample(b []byte, format, bits int) float64 {
+	if format == wavFormatFloat {
+		if bits == 64 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	}
+	switch bits {
+	case 8:
+		return (float64(b[0]) - 128) / 128
+	case 16:
+		return float64(int16(binary.LittleEndian.Uint16(b))) / 32768
+	case 24:
+		v := int32(b[0]) | int32(b[1])<<8 | int32(b[2])<<16
+		if v&0x800000 != 0 {
+			v |= ^0xFFFFFF
+		}
+		return float64(v) / 8388608
+	case 32:
+		return float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648
+	}
+	return 0
+}
+
+// isFloatExtensible reports whether a WAVE_FORMAT_EXTENSIBLE fmt chunk
+// carries the IEEE float subformat.
+func isFloatExtensible(data []byte) bool {
+	offset := 12
+	for offset+8 <= len(data) {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		if id == "fmt " {
+			if size < 40 || body+26 > len(data) {
+				return false
+			}
+			return binary.LittleEndian.Uint16(data[body+24:body+26]) == wavFormatFloat
+		}
+		offset = body + size + size%2
+	}
+	return false
+}
+
+// WAVWriter streams mono 16-bit PCM to a WAV file. The header sizes are
+// filled in by Close, so the destination must be seekable.
+type WAVWriter struct {
+	w          io.WriteSeeker
+	sampleRate int
+	dataSize   int64
+	buf        []byte
+}
+
+// NewWAVWriter writes a placeholder header and returns a writer for the samples.
+func NewWAVWriter(w io.WriteSeeker, sampleRate int) (*WAVWriter, error) {
+	ww := &WAVWriter{w: w, sampleRate: sampleRate}
+	if err := ww.writeHeader(); err != nil {
+		return nil, err
+	}
+	return ww, nil
+}
+
+// Write appends samples, clamping them to [-1, 1].
+func (ww *WAVWriter) Write(samples []float32) error {
+	if cap(ww.buf) < len(samples)*2 {
+		ww.buf = make([]byte, len(samples)*2)
+	}
+	buf := ww.buf[:len(samples)*2]
+	for i, s := range samples {
+		v := math.Max(-1, math.Min(1, float64(s)))
+		binary.LittleEndian.PutUint16(buf[i*2:], uint16(int16(math.Round(v*32767))))
+	}
+	n, err := ww.w.Write(buf)
+	ww.dataSize += int64(n)
+	return err
+}
+
+// Close rewrites the header with the final sizes.
+func (ww *WAVWriter) Close() error {
+	if _, err := ww.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := ww.writeHeader(); err != nil {
+		return err
+	}
+	_, err := ww.w.Seek(0, io.SeekEnd)
+	return err
+}
+
+func (ww *WAVWriter) writeHeader() error {
+	const channels, bits = 1, 16
+	header := make([]byte, 44)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(36+ww.dataSize))
+	copy(header[8:], "WAVE")
+	copy(header[12:], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)
+	binary.LittleEndian.PutUint16(header[20:], wavFormatPCM)
+	binary.LittleEndian.PutUint16(header[22:], channels)
+	binary.LittleEndian.PutUint32(header[24:], uint32(ww.sampleRate))
+	binary.LittleEndian.PutUint32(header[28:], uint32(ww.sampleRate*channels*bits/8))
+	binary.LittleEndian.PutUint16(header[32:], channels*bits/8)
+	binary.LittleEndian.PutUint16(header[34:], bits)
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], uint32(ww.dataSize))
+	_, err := ww.w.Write(header)
+	return err
+}
+
+// Resample converts pcm to the given sample rate with linear interpolation.
+func Resample(pcm *PCM, rate int) *PCM {
+	if pcm == nil || rate <= 0 || pcm.SampleRate == rate || pcm.SampleRate == 0 || len(pcm.Samples) == 0 {
+		return pcm
+	}
+	n := int(int64(len(pcm.Samples)) * int64(rate) / int64(pcm.SampleRate))
+	out := make([]float32, n)
+	step := float64(pcm.SampleRate) / float64(rate)
+	last := len(pcm.Samples) - 1
+	for i := range out {
+		pos := float64(i) * step
+		j := int(pos)
+		if j >= last {
+			out[i] = pcm.Samples[last]
+			continue
+		}
+		frac := float32(pos - float64(j))
+		out[i] = pcm.Samples[j]*(1-frac) + pcm.Samples[j+1]*frac
+	}
+	return &PCM{SampleRate: rate, Samples: out}
+}
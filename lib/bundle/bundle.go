@@ -0,0 +1,769 @@
+// Package bundle packs a conversation into a single .vibecast archive that
+// can be handed to someone else and imported into their library.
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nraghuveer/vibecast/lib/atomicfile"
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/models"
+	"github.com/nraghuveer/vibecast/lib/publish"
+	"github.com/nraghuveer/vibecast/lib/storage"
+)
+
+const (
+	// Extension is the file extension of an exported conversation.
+	Extension = ".vibecast"
+
+	// FormatVersion is written into every manifest. Import refuses newer
+	// bundles rather than dropping what it doesn't understand.
+	FormatVersion = 1
+
+	manifestName   = "manifest.json"
+	transcriptName = "transcript.jsonl"
+	showNotesName  = "show_notes.md"
+	audioDirName   = "audio"
+
+	// maxManifestBytes caps the manifest, which is read into memory.
+	// maxEntryBytes caps every other entry, which is staged on disk.
+	maxManifestBytes = 16 << 20
+	maxEntryBytes    = 2 << 30
+)
+
+// Manifest describes a bundle. Files maps every other entry in the archive
+// to its SHA-256, which import checks before writing anything.
+type Manifest struct {
+	Version      int               `json:"version"`
+	ExportedAt   time.Time         `json:"exported_at"`
+	Conversation Conversation      `json:"conversation"`
+	Template     *models.Template  `json:"template,omitempty"`
+	Voices       []Voice           `json:"voices,omitempty"`
+	Provider     Provider          `json:"provider"`
+	Audio        []Audio           `json:"audio,omitempty"`
+	Files        map[string]string `json:"files"`
+}
+
+// Conversation is the conversations row.
+type Conversation struct {
+	ID                 string     `json:"id"`
+	Title              string     `json:"title"`
+	Topic              string     `json:"topic"`
+	Persona            string     `json:"persona"`
+	VoiceID            string     `json:"voice_id"`
+	VoiceName          string     `json:"voice_name"`
+	VoiceProfileID     string     `json:"voice_profile_id,omitempty"`
+	HostVoiceProfileID string     `json:"host_voice_profile_id,omitempty"`
+	Provider           string     `json:"provider"`
+	CreatedAt          time.Time  `json:"created_at"`
+	EndedAt            *time.Time `json:"ended_at,omitempty"`
+	AudioPrunedAt      *time.Time `json:"audio_pruned_at,omitempty"`
+}
+
+// Voice is a voice profile the conversation was spoken in.
+type Voice struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Provider     string  `json:"provider"`
+	VoiceID      string  `json:"voice_id"`
+	Speed        float64 `json:"speed,omitempty"`
+	Instructions string  `json:"instructions,omitempty"`
+	Description  string  `json:"description,omitempty"`
+}
+
+// Provider records the models the conversation was made with. API keys are
+// never exported.
+type Provider struct {
+	Name      string `json:"name"`
+	ChatModel string `json:"chat_model,omitempty"`
+	STTModel  string `json:"stt_model,omitempty"`
+	TTSModel  string `json:"tts_model,omitempty"`
+	TTSFormat string `json:"tts_format,omitempty"`
+}
+
+// Audio is an audio_files row; the clip itself is stored under audio/.
+type Audio struct {
+	Index      int    `json:"index"`
+	Filename   string `json:"filename"`
+	Speaker    string `json:"speaker"`
+	MessageID  string `json:"message_id,omitempty"`
+	Format     string `json:"format"`
+	DurationMs int64  `json:"duration_ms"`
+	SampleRate int    `json:"sample_rate"`
+	Channels   int    `json:"channels"`
+	SizeBytes  int64  `json:"size_bytes"`
+}
+
+// ExportResult describes a written bundle.
+type ExportResult struct {
+	Path     string
+	Messages int
+	Clips    int
+	Warnings []string
+}
+
+// ImportResult describes an imported conversation.
+type ImportResult struct {
+	ConversationID string
+	Title          string
+	Renamed        bool // the bundle's id was taken, so new ids were assigned
+	Messages       int
+	Clips          int
+	Warnings       []string
+}
+
+// DefaultFileName names a bundle after the conversation title and id.
+func DefaultFileName(c db.Conversation) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(c.Title) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	short := c.ID
+	if len(short) > 8 {
+		short = short[:8]
+	}
+	if slug == "" {
+		return short + Extension
+	}
+	return slug + "-" + short + Extension
+}
+
+// Export writes conversationID to outPath as a .vibecast archive. A bundle
+// is meant to be handed on, so encrypted data is written decrypted.
+func Export(database *db.DB, conversationID, outPath string) (*ExportResult, error) {
+	c, err := database.GetConversation(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := storage.LoadMessages(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := database.GetAudioFiles(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	audioDir, err := storage.GetAudioDir(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExportResult{Path: outPath, Messages: len(messages)}
+	manifest := Manifest{
+		Version:      FormatVersion,
+		ExportedAt:   time.Now().UTC(),
+		Conversation: conversationRecord(*c),
+		Provider:     providerRecord(c.Provider),
+		Files:        map[string]string{},
+	}
+	manifest.Template, err = findTemplate(database, *c)
+	if err != nil {
+		return nil, err
+	}
+	manifest.Voices, err = conversationVoices(database, *c)
+	if err != nil {
+		return nil, err
+	}
+
+	var transcript bytes.Buffer
+	for _, msg := range messages {
+		line, err := json.Marshal(msg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode transcript message: %w", err)
+		}
+		transcript.Write(append(line, '\n'))
+	}
+	showNotes := []byte(publish.ShowNotes(*c, messages) + "\n")
+	manifest.Files[transcriptName] = checksum(transcript.Bytes())
+	manifest.Files[showNotesName] = checksum(showNotes)
+
+	var clips []string
+	for _, f := range files {
+		clipPath := filepath.Join(audioDir, f.Filename)
+		sum, err := checksumFile(clipPath)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped %s: %v", f.Filename, err))
+			continue
+		}
+		name := path.Join(audioDirName, f.Filename)
+		manifest.Files[name] = sum
+		manifest.Audio = append(manifest.Audio, audioRecord(f))
+		clips = append(clips, clipPath)
+	}
+	result.Clips = len(clips)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	err = atomicfile.WriteFunc(outPath, 0644, func(w io.Writer) error {
+		return writeArchive(w, manifestData, transcript.Bytes(), showNotes, clips)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return result, nil
+}
+
+func writeArchive(out io.Writer, manifest, transcript, showNotes []byte, clips []string) error {
+	zw := zip.NewWriter(out)
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{
+		{manifestName, manifest},
+		{transcriptName, transcript},
+		{showNotesName, showNotes},
+	} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: entry.name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", entry.name, err)
+		}
+		if _, err := w.Write(entry.data); err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", entry.name, err)
+		}
+	}
+
+	for _, clip := range clips {
+		// Audio is already compressed or barely compressible; store it as is.
+		name := path.Join(audioDirName, filepath.Base(clip))
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+		if err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+		}
+		data, err := crypt.ReadFile(clip)
+		if err != nil {
+			return fmt.Errorf("failed to read audio file: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// Import adds the conversation in a .vibecast archive to the library. Every
+// checksum is verified first. If the conversation's id is already taken the
+// conversation and its messages get new ids. A failed import leaves nothing
+// behind.
+func Import(database *db.DB, bundlePath string) (*ImportResult, error) {
+	zr, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer zr.Close()
+
+	entries := map[string]*zip.File{}
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+
+	manifest, err := readManifest(entries)
+	if err != nil {
+		return nil, err
+	}
+	staged, err := verify(entries, manifest)
+	if err != nil {
+		return nil, err
+	}
+	defer staged.remove()
+	transcript, err := staged.read(transcriptName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Title: manifest.Conversation.Title}
+	if _, err := config.GetProviderConfig(manifest.Provider.Name); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("provider %q is not configured; continuing the conversation will fail until it is", manifest.Provider.Name))
+	}
+
+	conversationID := manifest.Conversation.ID
+	taken, err := idTaken(database, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	messages := storage.DecodeTranscript(transcript)
+	messageIDs := map[string]string{}
+	if taken {
+		conversationID = uuid.New().String()
+		result.Renamed = true
+		for _, msg := range messages {
+			messageIDs[msg.ID] = uuid.New().String()
+		}
+	}
+	result.ConversationID = conversationID
+
+	var created references
+	rollback := func(err error) (*ImportResult, error) {
+		database.DeleteConversation(conversationID)
+		storage.DeleteConversationDir(conversationID)
+		created.remove(database)
+		return nil, err
+	}
+
+	created, err = importReferences(database, manifest)
+	if err != nil {
+		return rollback(err)
+	}
+	if err := createConversation(database, conversationID, manifest, result.Renamed); err != nil {
+		return rollback(err)
+	}
+
+	if _, err := storage.CreateConversationDir(conversationID); err != nil {
+		return rollback(err)
+	}
+	audioDir, err := storage.GetAudioDir(conversationID)
+	if err != nil {
+		return rollback(err)
+	}
+	for _, a := range manifest.Audio {
+		data, err := staged.read(path.Join(audioDirName, a.Filename))
+		if err != nil {
+			return rollback(err)
+		}
+		if err := crypt.WriteFile(filepath.Join(audioDir, a.Filename), data, 0644); err != nil {
+			return rollback(fmt.Errorf("failed to write audio file: %w", err))
+		}
+		file := a.audioFile(conversationID)
+		if newID, ok := messageIDs[file.MessageID]; ok {
+			file.MessageID = newID
+		}
+		if err := database.RecordAudioFile(file); err != nil {
+			return rollback(err)
+		}
+		result.Clips++
+	}
+
+	if err := storage.CreateTranscript(conversationID); err != nil {
+		return rollback(err)
+	}
+	for _, msg := range messages {
+		if newID, ok := messageIDs[msg.ID]; ok {
+			msg.ID = newID
+		}
+		if newID, ok := messageIDs[msg.TakeOf]; ok {
+			msg.TakeOf = newID
+		}
+		if _, err := storage.AppendRecord(database, conversationID, msg); err != nil {
+			return rollback(err)
+		}
+		result.Messages++
+	}
+
+	return result, nil
+}
+
+func readManifest(entries map[string]*zip.File) (*Manifest, error) {
+	f, ok := entries[manifestName]
+	if !ok {
+		return nil, fmt.Errorf("not a vibecast bundle: %s is missing", manifestName)
+	}
+	data, err := readEntry(f, maxManifestBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if manifest.Version < 1 || manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d (this vibecast reads up to %d)", manifest.Version, FormatVersion)
+	}
+	if manifest.Conversation.ID == "" {
+		return nil, fmt.Errorf("manifest has no conversation id")
+	}
+	// The id names the conversation's folder, so it must not be a path.
+	if _, err := uuid.Parse(manifest.Conversation.ID); err != nil || filepath.Base(manifest.Conversation.ID) != manifest.Conversation.ID {
+		return nil, fmt.Errorf("manifest has an invalid conversation id %q", manifest.Conversation.ID)
+	}
+	return &manifest, nil
+}
+
+// staging holds a bundle's verified files in a temp directory until they
+// are written to the library.
+type staging struct {
+	dir   string
+	files map[string]string // entry name to its temp file
+}
+
+func (s *staging) read(name string) ([]byte, error) {
+	tmp, ok := s.files[name]
+	if !ok {
+		return nil, fmt.Errorf("bundle is missing %s", name)
+	}
+	data, err := os.ReadFile(tmp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from bundle: %w", name, err)
+	}
+	return data, nil
+}
+
+func (s *staging) remove() {
+	os.RemoveAll(s.dir)
+}
+
+// verify copies every file the manifest lists to a temp directory, checking
+// its SHA-256 on the way. Names that could escape the conversation folder,
+// and clip names that aren't clips, are rejected.
+func verify(entries map[string]*zip.File, manifest *Manifest) (*staging, error) {
+	for _, a := range manifest.Audio {
+		if path.Base(a.Filename) != a.Filename || !storage.IsClipName(a.Filename) {
+			return nil, fmt.Errorf("manifest lists an invalid clip name: %s", a.Filename)
+		}
+		name := path.Join(audioDirName, a.Filename)
+		if _, ok := manifest.Files[name]; !ok {
+			return nil, fmt.Errorf("manifest lists clip %s without a checksum", a.Filename)
+		}
+	}
+	if _, ok := manifest.Files[transcriptName]; !ok {
+		return nil, fmt.Errorf("bundle is missing %s", transcriptName)
+	}
+
+	dir, err := os.MkdirTemp("", "vibecast-import-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	staged := &staging{dir: dir, files: map[string]string{}}
+	for name, want := range manifest.Files {
+		if !validEntryName(name) {
+			staged.remove()
+			return nil, fmt.Errorf("bundle contains an invalid path: %s", name)
+		}
+		f, ok := entries[name]
+		if !ok {
+			staged.remove()
+			return nil, fmt.Errorf("bundle is missing %s", name)
+		}
+		tmp := filepath.Join(dir, fmt.Sprintf("%d", len(staged.files)))
+		got, err := extractEntry(f, tmp)
+		if err != nil {
+			staged.remove()
+			return nil, err
+		}
+		if got != want {
+			staged.remove()
+			return nil, fmt.Errorf("checksum mismatch for %s: bundle is corrupt or was modified", name)
+		}
+		staged.files[name] = tmp
+	}
+	return staged, nil
+}
+
+func validEntryName(name string) bool {
+	switch name {
+	case manifestName, transcriptName, showNotesName:
+		return true
+	}
+	dir, file := path.Split(name)
+	return dir == audioDirName+"/" && file != "" && file != "." && file != ".." && !strings.ContainsAny(file, `/\`)
+}
+
+// readEntry reads an entry into memory, failing if it is over limit bytes.
+func readEntry(f *zip.File, limit int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from bundle: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from bundle: %w", f.Name, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s in bundle is too large", f.Name)
+	}
+	return data, nil
+}
+
+// extractEntry streams an entry to dst and returns its SHA-256, failing if
+// it is over maxEntryBytes.
+func extractEntry(f *zip.File, dst string) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from bundle: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to stage %s: %w", f.Name, err)
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(out, h), io.LimitReader(rc, maxEntryBytes+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from bundle: %w", f.Name, err)
+	}
+	if n > maxEntryBytes {
+		return "", fmt.Errorf("%s in bundle is too large", f.Name)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func idTaken(database *db.DB, id string) (bool, error) {
+	exists, err := database.ConversationExists(id)
+	if err != nil || exists {
+		return exists, err
+	}
+	return storage.ConversationExists(id)
+}
+
+// references are the template and voices an import added, so a failed
+// import can take them out again.
+type references struct {
+	template string
+	voices   []string
+}
+
+func (r references) remove(database *db.DB) {
+	if r.template != "" {
+		database.DeleteTemplate(r.template)
+	}
+	for _, id := range r.voices {
+		database.DeleteVoice(id)
+	}
+}
+
+// importReferences adds the bundle's template and voices unless the library
+// already has them; local copies are never overwritten. It returns what it
+// added, even when it fails part way.
+func importReferences(database *db.DB, manifest *Manifest) (references, error) {
+	var created references
+	if t := manifest.Template; t != nil && t.ID != "" {
+		exists, err := database.TemplateExists(t.ID)
+		if err != nil {
+			return created, err
+		}
+		if !exists {
+			if err := database.CreateTemplate(*t); err != nil {
+				return created, err
+			}
+			created.template = t.ID
+		}
+	}
+
+	for _, v := range manifest.Voices {
+		exists, err := database.VoiceExists(v.ID)
+		if err != nil {
+			return created, err
+		}
+		if !exists {
+			if err := database.CreateVoice(v.profile()); err != nil {
+				return created, err
+			}
+			created.voices = append(created.voices, v.ID)
+		}
+	}
+	return created, nil
+}
+
+func createConversation(database *db.DB, id string, manifest *Manifest, renamed bool) error {
+	rec := manifest.Conversation
+	conv := models.Conversation{
+		ID:                 id,
+		Title:              rec.Title,
+		Topic:              rec.Topic,
+		Persona:            rec.Persona,
+		VoiceID:            rec.VoiceID,
+		VoiceName:          rec.VoiceName,
+		VoiceProfileID:     rec.VoiceProfileID,
+		HostVoiceProfileID: rec.HostVoiceProfileID,
+		Provider:           rec.Provider,
+		CreatedAt:          rec.CreatedAt,
+	}
+	if renamed {
+		conv.Title += " (imported)"
+	}
+	if conv.VoiceProfileID != "" {
+		exists, err := database.VoiceExists(conv.VoiceProfileID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			conv.VoiceProfileID = ""
+		}
+	}
+
+	if err := database.CreateConversation(conv); err != nil {
+		return err
+	}
+	if rec.EndedAt != nil {
+		if err := database.UpdateConversationEndedAt(id, *rec.EndedAt); err != nil {
+			database.DeleteConversation(id)
+			return err
+		}
+	}
+	if rec.AudioPrunedAt != nil {
+		if err := database.MarkAudioPruned(id, *rec.AudioPrunedAt); err != nil {
+			database.DeleteConversation(id)
+			return err
+		}
+	}
+	return nil
+}
+
+// findTemplate returns the template the conversation was started from, if
+// one with the same topic and persona still exists.
+func findTemplate(database *db.DB, c db.Conversation) (*models.Template, error) {
+	templates, err := database.GetAllTemplates()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		if t.Topic == c.Topic && t.Persona == c.Persona {
+			return &models.Template{ID: t.ID, Name: t.Name, Topic: t.Topic, Persona: t.Persona}, nil
+		}
+	}
+	return nil, nil
+}
+
+func conversationVoices(database *db.DB, c db.Conversation) ([]Voice, error) {
+	var voices []Voice
+	seen := map[string]bool{}
+	for _, id := range []string{c.VoiceProfileID, c.HostVoiceProfileID} {
+		if id == "" || id == "none" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		exists, err := database.VoiceExists(id)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		v, err := database.GetVoice(id)
+		if err != nil {
+			return nil, err
+		}
+		voices = append(voices, Voice{
+			ID:           v.ID,
+			Name:         v.Name,
+			Provider:     v.Provider,
+			VoiceID:      v.VoiceID,
+			Speed:        v.Speed,
+			Instructions: v.Instructions,
+			Description:  v.Description,
+		})
+	}
+	return voices, nil
+}
+
+func (v Voice) profile() models.VoiceProfile {
+	return models.VoiceProfile{
+		ID:           v.ID,
+		Name:         v.Name,
+		Provider:     v.Provider,
+		VoiceID:      v.VoiceID,
+		Speed:        v.Speed,
+		Instructions: v.Instructions,
+		Description:  v.Description,
+	}
+}
+
+func conversationRecord(c db.Conversation) Conversation {
+	rec := Conversation{
+		ID:                 c.ID,
+		Title:              c.Title,
+		Topic:              c.Topic,
+		Persona:            c.Persona,
+		VoiceID:            c.VoiceID,
+		VoiceName:          c.VoiceName,
+		VoiceProfileID:     c.VoiceProfileID,
+		HostVoiceProfileID: c.HostVoiceProfileID,
+		Provider:           c.Provider,
+		CreatedAt:          c.CreatedAt,
+	}
+	if c.EndedAt.Valid {
+		ended := c.EndedAt.Time
+		rec.EndedAt = &ended
+	}
+	if c.AudioPrunedAt.Valid {
+		pruned := c.AudioPrunedAt.Time
+		rec.AudioPrunedAt = &pruned
+	}
+	return rec
+}
+
+func providerRecord(name string) Provider {
+	rec := Provider{Name: name}
+	if cfg, err := config.GetProviderConfig(name); err == nil {
+		rec.ChatModel = cfg.ChatModel
+		rec.STTModel = cfg.STTModel
+		rec.TTSModel = cfg.TTSModel
+		rec.TTSFormat = config.GetProviderTTSFormat(name)
+	}
+	return rec
+}
+
+func audioRecord(f models.AudioFile) Audio {
+	return Audio{
+		Index:      f.Index,
+		Filename:   f.Filename,
+		Speaker:    f.Speaker.String(),
+		MessageID:  f.MessageID,
+		Format:     f.Format,
+		DurationMs: f.Duration.Milliseconds(),
+		SampleRate: f.SampleRate,
+		Channels:   f.Channels,
+		SizeBytes:  f.SizeBytes,
+	}
+}
+
+func (a Audio) audioFile(conversationID string) models.AudioFile {
+	return models.AudioFile{
+		ConversationID: conversationID,
+		Index:          a.Index,
+		Filename:       a.Filename,
+		Speaker:        models.ParseSpeakerType(a.Speaker),
+		MessageID:      a.MessageID,
+		Format:         a.Format,
+		Duration:       time.Duration(a.DurationMs) * time.Millisecond,
+		SampleRate:     a.SampleRate,
+		Channels:       a.Channels,
+		SizeBytes:      a.SizeBytes,
+	}
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// checksumFile hashes a file's contents, decrypted if it is encrypted.
+func checksumFile(path string) (string, error) {
+	data, err := crypt.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return checksum(data), nil
+}
@@ -0,0 +1,157 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testConversationID = "a9a24e10-01c9-495d-b35a-6c3c04a13c7e"
+
+// openBundle builds an archive in memory from manifest and files and
+// returns its entries as Import sees them.
+func openBundle(t *testing.T, manifest Manifest, files map[string][]byte) map[string]*zip.File {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files[manifestName] = data
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := map[string]*zip.File{}
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+	return entries
+}
+
+// validManifest returns a manifest for a transcript and one clip, with the
+// files it describes.
+func validManifest() (Manifest, map[string][]byte) {
+	transcript := []byte(`{"v":1,"id":"m1","speaker":"Host","content":"hi"}` + "\n")
+	clip := []byte("RIFF")
+	return Manifest{
+		Version:      FormatVersion,
+		Conversation: Conversation{ID: testConversationID},
+		Audio:        []Audio{{Index: 1, Filename: "001.wav"}},
+		Files: map[string]string{
+			transcriptName:  checksum(transcript),
+			"audio/001.wav": checksum(clip),
+		},
+	}, map[string][]byte{
+		transcriptName:  transcript,
+		"audio/001.wav": clip,
+	}
+}
+
+func TestReadManifestRejectsUnsafeIDs(t *testing.T) {
+	for _, id := range []string{"", "../../somewhere", "a/b", "..", "not-a-uuid", "../" + testConversationID} {
+		manifest, files := validManifest()
+		manifest.Conversation.ID = id
+		if _, err := readManifest(openBundle(t, manifest, files)); err == nil {
+			t.Errorf("readManifest accepted conversation id %q", id)
+		}
+	}
+
+	manifest, files := validManifest()
+	if _, err := readManifest(openBundle(t, manifest, files)); err != nil {
+		t.Errorf("readManifest rejected a valid bundle: %v", err)
+	}
+}
+
+func TestVerifyRejectsClipTraversal(t *testing.T) {
+	for _, name := range []string{"../transcript.jsonl", "../../evil.wav", "sub/001.wav", "transcript.jsonl"} {
+		manifest, files := validManifest()
+		manifest.Audio = []Audio{{Index: 1, Filename: name}}
+		staged, err := verify(openBundle(t, manifest, files), &manifest)
+		if err == nil {
+			staged.remove()
+			t.Errorf("verify accepted clip name %q", name)
+		}
+	}
+}
+
+func TestVerifyRejectsInvalidEntryNames(t *testing.T) {
+	for _, name := range []string{"../evil", "audio/../../evil", "audio/", "other/001.wav"} {
+		manifest, files := validManifest()
+		// The name is rejected before the archive is looked at, so no
+		// entry is needed.
+		manifest.Files[name] = checksum([]byte("x"))
+		staged, err := verify(openBundle(t, manifest, files), &manifest)
+		if err == nil {
+			staged.remove()
+			t.Errorf("verify accepted entry %q", name)
+		} else if !strings.Contains(err.Error(), "invalid path") {
+			t.Errorf("entry %q: unexpected error %v", name, err)
+		}
+	}
+}
+
+func TestVerifyRejectsChecksumMismatch(t *testing.T) {
+	manifest, files := validManifest()
+	files["audio/001.wav"] = []byte("RIFX")
+	staged, err := verify(openBundle(t, manifest, files), &manifest)
+	if err == nil {
+		staged.remove()
+		t.Fatal("verify accepted a modified clip")
+	}
+	if !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestVerifyRejectsMissingEntries(t *testing.T) {
+	manifest, files := validManifest()
+	delete(files, "audio/001.wav")
+	if staged, err := verify(openBundle(t, manifest, files), &manifest); err == nil {
+		staged.remove()
+		t.Error("verify accepted a bundle without a listed clip")
+	}
+
+	manifest, files = validManifest()
+	delete(manifest.Files, transcriptName)
+	if staged, err := verify(openBundle(t, manifest, files), &manifest); err == nil {
+		staged.remove()
+		t.Error("verify accepted a bundle without a transcript")
+	}
+}
+
+func TestVerifyStagesFiles(t *testing.T) {
+	manifest, files := validManifest()
+	staged, err := verify(openBundle(t, manifest, files), &manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer staged.remove()
+
+	for _, name := range []string{transcriptName, "audio/001.wav"} {
+		data, err := staged.read(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, files[name]) {
+			t.Errorf("%s staged as %q, want %q", name, data, files[name])
+		}
+	}
+}
@@ -0,0 +1,373 @@
+// Package captions builds SRT and WebVTT subtitles for a rendered episode
+// from its clip timeline and the transcript.
+package captions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/audio"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/episode"
+	"github.com/nraghuveer/vibecast/lib/models"
+	"github.com/nraghuveer/vibecast/lib/storage"
+)
+
+// Caption sizing: at most two lines of lineChars, on screen for no longer
+// than maxCueDuration. A cue ends early at a sentence once it holds
+// sentenceChars.
+const (
+	lineChars      = 42
+	cueChars       = 2 * lineChars
+	sentenceChars  = 24
+	maxCueDuration = 6 * time.Second
+)
+
+// Cue is one caption.
+type Cue struct {
+	Start   time.Duration
+	End     time.Duration
+	Speaker models.SpeakerType
+	Text    string
+}
+
+// Span is speech recognized in a clip, with offsets from the clip's start.
+type Span struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// Aligner recognizes the speech in a WAV file, for timing captions to it.
+type Aligner func(ctx context.Context, wavPath string) ([]Span, error)
+
+// alignSampleRate is the rate non-WAV clips are decoded at for an Aligner;
+// speech recognizers want no more than 16 kHz.
+const alignSampleRate = 16000
+
+// Result is a conversation's captions.
+type Result struct {
+	Cues     []Cue
+	Clips    int
+	Aligned  int      // clips timed by their recognized speech
+	Warnings []string // clips that couldn't be aligned, with the reason
+}
+
+// ForConversation captions a conversation as RenderConversation would render
+// it with opts. With align, each clip is timed by its recognized speech;
+// clips it fails on fall back to even timing.
+func ForConversation(ctx context.Context, database *db.DB, conversationID string, opts episode.Options, align Aligner) (*Result, error) {
+	clips, err := episode.ConversationClips(database, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	timeline, err := episode.Layout(ctx, clips, opts)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := storage.LoadMessages(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Clips: len(clips)}
+	spans := map[string][]Span{}
+	if align != nil {
+		for _, clip := range clips {
+			clipSpans, err := alignClip(ctx, align, clip.Path)
+			if err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", filepath.Base(clip.Path), err))
+				continue
+			}
+			if len(clipSpans) > 0 {
+				spans[clip.Path] = clipSpans
+				result.Aligned++
+			}
+		}
+	}
+
+	result.Cues = Build(timeline, storage.Canonical(messages), spans)
+	return result, nil
+}
+
+// alignClip runs align on a clip, decoding it to a temporary WAV file first
+// when it's in another format or encrypted.
+func alignClip(ctx context.Context, align Aligner, clipPath string) ([]Span, error) {
+	if strings.EqualFold(filepath.Ext(clipPath), "."+audio.FormatWAV) {
+		source, cleanup, err := crypt.PlainFile(clipPath)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		return align(ctx, source)
+	}
+
+	pcm, err := audio.DecodeFile(ctx, clipPath, alignSampleRate)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp("", "vibecast-align-*.wav")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	writer, err := audio.NewWAVWriter(f, pcm.SampleRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := writer.Write(pcm.Samples); err != nil {
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return align(ctx, f.Name())
+}
+
+// Build captions the clips in timeline with the messages they voice. A
+// message's words are shared among its clips, then timed within each clip:
+// by the clip's recognized spans when spans has any for its path, otherwise
+// spread evenly by word. Clips that voice no known message get no captions.
+func Build(timeline []episode.Placement, messages []storage.Message, spans map[string][]Span) []Cue {
+	text := map[string]string{}
+	for _, msg := range messages {
+		text[msg.ID] = msg.Content
+	}
+
+	var cues []Cue
+	for start := 0; start < len(timeline); {
+		messageID := timeline[start].Clip.MessageID
+		end := start + 1
+		for end < len(timeline) && timeline[end].Clip.MessageID == messageID {
+			end++
+		}
+		clips := timeline[start:end]
+		start = end
+
+		words := strings.Fields(text[messageID])
+		if messageID == "" || len(words) == 0 {
+			continue
+		}
+		for i, clipWords := range shareWords(words, clips, spans) {
+			cues = append(cues, clipCues(clips[i], clipWords, spans[clips[i].Clip.Path])...)
+		}
+	}
+	return cues
+}
+
+// shareWords splits a message's words among the clips voicing it, in
+// proportion to each clip's recognized words when every clip has spans, or
+// to its duration otherwise.
+func shareWords(words []string, clips []episode.Placement, spans map[string][]Span) [][]string {
+	weights := make([]float64, len(clips))
+	recognized := true
+	for i, clip := range clips {
+		n := 0
+		for _, span := range spans[clip.Clip.Path] {
+			n += len(strings.Fields(span.Text))
+		}
+		if n == 0 {
+			recognized = false
+		}
+		weights[i] = float64(n)
+	}
+	if !recognized {
+		for i, clip := range clips {
+			weights[i] = clip.Duration.Seconds()
+		}
+	}
+
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+
+	shares := make([][]string, len(clips))
+	var cumulative float64
+	from := 0
+	for i, w := range weights {
+		cumulative += w
+		to := len(words)
+		if i < len(clips)-1 && total > 0 {
+			to = min(int(float64(len(words))*cumulative/total+0.5), len(words))
+		}
+		shares[i] = words[from:max(to, from)]
+		from = max(to, from)
+	}
+	return shares
+}
+
+// clipCues groups a clip's words into cues and times them.
+func clipCues(clip episode.Placement, words []string, spans []Span) []Cue {
+	if len(words) == 0 {
+		return nil
+	}
+	times := wordTimes(len(words), clip.Duration, spans)
+	end := clip.Duration
+	if len(spans) > 0 {
+		end = min(spans[len(spans)-1].End, clip.Duration)
+	}
+
+	var cues []Cue
+	first := 0
+	for first < len(words) {
+		last := first
+		length := len(words[first])
+		for last+1 < len(words) {
+			if length >= sentenceChars && endsSentence(words[last]) {
+				break
+			}
+			next := length + 1 + len(words[last+1])
+			if next > cueChars || times[last+1]-times[first] >= maxCueDuration {
+				break
+			}
+			length = next
+			last++
+		}
+
+		cueEnd := end
+		if last+1 < len(words) {
+			cueEnd = times[last+1]
+		}
+		cues = append(cues, Cue{
+			Start:   clip.Start + times[first],
+			End:     clip.Start + max(cueEnd, times[first]),
+			Speaker: clip.Clip.Speaker,
+			Text:    wrap(strings.Join(words[first:last+1], " ")),
+		})
+		first = last + 1
+	}
+	return cues
+}
+
+// wordTimes returns when each of n words starts, from the clip's start.
+// With spans, the words are laid over the recognized words in order, so the
+// transcript's wording is kept while the timing follows the speech.
+func wordTimes(n int, duration time.Duration, spans []Span) []time.Duration {
+	var recognized []time.Duration
+	for _, span := range spans {
+		spanWords := len(strings.Fields(span.Text))
+		for i := 0; i < spanWords; i++ {
+			recognized = append(recognized, span.Start+(span.End-span.Start)*time.Duration(i)/time.Duration(spanWords))
+		}
+	}
+
+	times := make([]time.Duration, n)
+	for i := range times {
+		if len(recognized) > 0 {
+			times[i] = recognized[i*len(recognized)/n]
+		} else {
+			times[i] = duration * time.Duration(i) / time.Duration(n)
+		}
+	}
+	return times
+}
+
+func endsSentence(word string) bool {
+	word = strings.TrimRight(word, `"')]`)
+	return strings.HasSuffix(word, ".") || strings.HasSuffix(word, "?") || strings.HasSuffix(word, "!")
+}
+
+// wrap breaks a cue longer than one line at the space nearest its middle.
+func wrap(text string) string {
+	if len(text) <= lineChars {
+		return text
+	}
+	mid := len(text) / 2
+	best := -1
+	for i, r := range text {
+		if r == ' ' && (best < 0 || abs(i-mid) < abs(best-mid)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return text
+	}
+	return text[:best] + "\n" + text[best+1:]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// SRT formats cues as SubRip. The speaker is named at the start of each turn.
+func SRT(cues []Cue) []byte {
+	var b bytes.Buffer
+	for i, cue := range cues {
+		text := cue.Text
+		if i == 0 || cues[i-1].Speaker != cue.Speaker {
+			text = cue.Speaker.String() + ": " + text
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, timestamp(cue.Start, ","), timestamp(cue.End, ","), text)
+	}
+	return b.Bytes()
+}
+
+// VTT formats cues as WebVTT, with each cue voiced by its speaker.
+func VTT(cues []Cue) []byte {
+	var b bytes.Buffer
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "%s --> %s\n<v %s>%s\n\n", timestamp(cue.Start, "."), timestamp(cue.End, "."), cue.Speaker, escapeVTT(cue.Text))
+	}
+	return b.Bytes()
+}
+
+// escapeVTT escapes the characters WebVTT cue text treats as markup.
+func escapeVTT(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// timestamp formats d as HH:MM:SS followed by sep and milliseconds.
+func timestamp(d time.Duration, sep string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// WriteFile writes cues to path as SubRip or WebVTT, chosen by its .srt or
+// .vtt extension, sealed when encryption is on.
+func WriteFile(path string, cues []Cue) error {
+	data, err := format(path, cues)
+	if err != nil {
+		return err
+	}
+	if err := crypt.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write captions: %w", err)
+	}
+	return nil
+}
+
+// WritePublicFile is WriteFile for captions handed to others, such as a
+// published feed's, which are always written in the clear.
+func WritePublicFile(path string, cues []Cue) error {
+	data, err := format(path, cues)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write captions: %w", err)
+	}
+	return nil
+}
+
+// format renders cues in the format named by path's extension.
+func format(path string, cues []Cue) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".srt":
+		return SRT(cues), nil
+	case ".vtt":
+		return VTT(cues), nil
+	}
+	return nil, fmt.Errorf("unsupported caption format %q", filepath.Ext(path))
+}
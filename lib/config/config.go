@@ -5,7 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/nraghuveer/vibecast/lib/atomicfile"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,10 +16,85 @@ type Config struct {
 	AI        AIConfig                  `yaml:"ai"`
 	UI        UIConfig                  `yaml:"ui"`
 	Providers map[string]ProviderConfig `yaml:"providers"`
+	Voices    []VoiceProfileConfig      `yaml:"voices,omitempty"`
+	Episode   EpisodeConfig             `yaml:"episode,omitempty"`
+	Publish   PublishConfig             `yaml:"publish,omitempty"`
+	Cache     CacheConfig               `yaml:"cache,omitempty"`
+	Retention RetentionConfig           `yaml:"retention,omitempty"`
 }
 
+// CacheConfig bounds the on-disk caches under <data_dir>/cache.
+type CacheConfig struct {
+	DisableTTS bool `yaml:"disable_tts,omitempty"`
+	TTSMaxMB   int  `yaml:"tts_max_mb,omitempty"` // least recently used clips are evicted past this
+}
+
+const defaultTTSCacheMB = 500
+
+// TTSEnabled reports whether synthesized speech is cached.
+func (c CacheConfig) TTSEnabled() bool {
+	return !c.DisableTTS
+}
+
+// TTSMaxBytes returns the TTS cache size limit.
+func (c CacheConfig) TTSMaxBytes() int64 {
+	mb := c.TTSMaxMB
+	if mb <= 0 {
+		mb = defaultTTSCacheMB
+	}
+	return int64(mb) << 20
+}
+
+// RetentionConfig bounds the disk used by conversation clips. Both limits
+// are off when zero; starred conversations are never pruned.
+type RetentionConfig struct {
+	AudioDays      int  `yaml:"audio_days,omitempty"`       // each clip is pruned once it is this old
+	MaxAudioMB     int  `yaml:"max_audio_mb,omitempty"`     // oldest clips are pruned past this total
+	DisableOnStart bool `yaml:"disable_on_start,omitempty"` // prune only with vibecast prune
+}
+
+// MaxAge returns how old a clip may get, or 0 for no limit.
+func (c RetentionConfig) MaxAge() time.Duration {
+	return time.Duration(max(c.AudioDays, 0)) * 24 * time.Hour
+}
+
+// MaxBytes returns the clip disk quota, or 0 for no limit.
+func (c RetentionConfig) MaxBytes() int64 {
+	return int64(max(c.MaxAudioMB, 0)) << 20
+}
+
+// EpisodeConfig controls how a conversation is rendered into one audio file.
+// Zero values fall back to the defaults in GetEpisodeConfig.
+type EpisodeConfig struct {
+	GapMs        int      `yaml:"gap_ms,omitempty"`         // silence between clips of the same speaker
+	SpeakerGapMs int      `yaml:"speaker_gap_ms,omitempty"` // silence when the speaker changes
+	LoudnessDBFS float64  `yaml:"loudness_dbfs,omitempty"`  // target speech loudness
+	PeakDBFS     float64  `yaml:"peak_dbfs,omitempty"`      // ceiling no sample may exceed
+	SampleRate   int      `yaml:"sample_rate,omitempty"`    // 0 uses the first clip's rate
+	Intro        string   `yaml:"intro,omitempty"`          // music bed played before the conversation
+	Outro        string   `yaml:"outro,omitempty"`          // music bed played after it
+	BedVolumeDB  float64  `yaml:"bed_volume_db,omitempty"`  // bed level relative to speech
+	BedOverlapMs int      `yaml:"bed_overlap_ms,omitempty"` // how long beds fade under speech
+	Formats      []string `yaml:"formats,omitempty"`        // extra encodings made with ffmpeg: mp3, opus
+}
+
+// VoiceProfileConfig is a user-defined voice profile.
+// Speed and Instructions are passed to providers that support them.
+type VoiceProfileConfig struct {
+	ID           string  `yaml:"id"`
+	Name         string  `yaml:"name"`
+	Provider     string  `yaml:"provider"`
+	VoiceID      string  `yaml:"voice_id"`
+	Speed        float64 `yaml:"speed,omitempty"`
+	Instructions string  `yaml:"instructions,omitempty"`
+	Description  string  `yaml:"description,omitempty"`
+}
+
+// GeneralConfig holds where VibeCast keeps its files. Paths may use ~ and
+// environment variables.
 type GeneralConfig struct {
-	DBPath string `yaml:"db_path"`
+	DataDir string `yaml:"data_dir,omitempty"` // root for conversations, caches and publish output
+	DBPath  string `yaml:"db_path,omitempty"`  // default <data_dir>/data.sqlite
 }
 
 type UIConfig struct {
@@ -25,6 +102,8 @@ type UIConfig struct {
 	TranscriptSide  TranscriptSide `yaml:"transcript_side"`
 	TranscriptWidth int            `yaml:"transcript_width"`
 	Wave            WaveConfig     `yaml:"wave"`
+	AudioPlayer     string         `yaml:"audio_player,omitempty"`
+	PlayHostAudio   bool           `yaml:"play_host_audio,omitempty"`
 }
 
 type WaveConfig struct {
@@ -45,6 +124,8 @@ type AIConfig struct {
 	SpeechToTextProvider string `yaml:"speech_to_text"`
 	TextToSpeechProvider string `yaml:"text_to_speech"`
 	ReasoningEffort      string `yaml:"reasoning_effort"`
+	HostVoice            string `yaml:"host_voice,omitempty"`  // voice profile id for the host's lines
+	TTSWorkers           int    `yaml:"tts_workers,omitempty"` // speech blocks synthesized concurrently
 }
 
 type ProviderConfig struct {
@@ -56,6 +137,11 @@ type ProviderConfig struct {
 	TTSURL       string `yaml:"tts_url"`
 	APIKey       string `yaml:"api_key"`
 	IsEnvVar     bool   `yaml:"is_env_var"`
+	STTBinary    string `yaml:"stt_binary,omitempty"`
+	STTLanguage  string `yaml:"stt_language,omitempty"`
+	STTThreads   int    `yaml:"stt_threads,omitempty"`
+	VoicesURL    string `yaml:"voices_url,omitempty"`
+	TTSFormat    string `yaml:"tts_format,omitempty"`
 }
 
 const (
@@ -63,8 +149,12 @@ const (
 	defaultConfigFile = "config.yml"
 	defaultDBFile     = "data.sqlite"
 	defaultProvider   = "groq"
+	defaultTTSFormat  = "wav"
 )
 
+// SupportedTTSFormats lists the audio formats accepted for tts_format.
+var SupportedTTSFormats = []string{"wav", "mp3", "opus", "flac"}
+
 var (
 	globalConfig *Config
 	configPath   string
@@ -72,12 +162,9 @@ var (
 
 func Load(configFilePath string) (*Config, error) {
 	if configFilePath == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
-		}
-		configFilePath = filepath.Join(homeDir, defaultConfigDir, defaultConfigFile)
+		configFilePath = DefaultConfigPath()
 	}
+	configFilePath = ExpandPath(configFilePath)
 
 	configPath = configFilePath
 
@@ -105,13 +192,7 @@ func Load(configFilePath string) (*Config, error) {
 }
 
 func createDefaultConfig() Config {
-	homeDir, _ := os.UserHomeDir()
-	defaultDBPath := filepath.Join(homeDir, defaultConfigDir, defaultDBFile)
-
 	return Config{
-		General: GeneralConfig{
-			DBPath: defaultDBPath,
-		},
 		AI: AIConfig{
 			ConversationProvider: defaultProvider,
 			SpeechToTextProvider: defaultProvider,
@@ -154,11 +235,6 @@ func createDefaultConfig() Config {
 }
 
 func (c *Config) setDefaults() {
-	if c.General.DBPath == "" {
-		homeDir, _ := os.UserHomeDir()
-		c.General.DBPath = filepath.Join(homeDir, defaultConfigDir, defaultDBFile)
-	}
-
 	if c.AI.ConversationProvider == "" {
 		c.AI.ConversationProvider = defaultProvider
 	}
@@ -229,13 +305,7 @@ func Save(cfg Config, configFilePath string) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	tempPath := configFilePath + ".tmp"
-	if err := os.WriteFile(tempPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write temporary config file: %w", err)
-	}
-
-	if err := os.Rename(tempPath, configFilePath); err != nil {
-		os.Remove(tempPath)
+	if err := atomicfile.Write(configFilePath, data, 0644); err != nil {
 		return fmt.Errorf("failed to replace config file: %w", err)
 	}
 
@@ -275,12 +345,19 @@ func Get() *Config {
 	return globalConfig
 }
 
+// GetDBPath returns general.db_path, or data.sqlite under the data root.
+// Older versions wrote the ~/.vibecast default into every config, so that
+// value follows the data root when a different one is chosen.
 func GetDBPath() string {
-	if globalConfig != nil && globalConfig.General.DBPath != "" {
-		return globalConfig.General.DBPath
+	defaultPath := filepath.Join(GetDataDir(), defaultDBFile)
+	if globalConfig == nil || globalConfig.General.DBPath == "" {
+		return defaultPath
 	}
-	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, defaultConfigDir, defaultDBFile)
+	dbPath := ExpandPath(globalConfig.General.DBPath)
+	if dbPath == filepath.Join(legacyDataDir(), defaultDBFile) {
+		return defaultPath
+	}
+	return dbPath
 }
 
 func GetConversationProvider() string {
@@ -304,6 +381,25 @@ func GetTextToSpeechProvider() string {
 	return defaultProvider
 }
 
+const defaultTTSWorkers = 3
+
+// GetTTSWorkers returns how many speech blocks may be synthesized at once.
+func GetTTSWorkers() int {
+	if globalConfig != nil && globalConfig.AI.TTSWorkers > 0 {
+		return globalConfig.AI.TTSWorkers
+	}
+	return defaultTTSWorkers
+}
+
+// GetHostVoice returns the default voice profile id for host lines, or ""
+// when host lines are not voiced.
+func GetHostVoice() string {
+	if globalConfig != nil {
+		return strings.TrimSpace(globalConfig.AI.HostVoice)
+	}
+	return ""
+}
+
 func GetReasoningEffort() string {
 	if globalConfig != nil {
 		return strings.TrimSpace(globalConfig.AI.ReasoningEffort)
@@ -320,6 +416,8 @@ func GetProviderConfig(provider string) (*ProviderConfig, error) {
 	if !exists {
 		return nil, fmt.Errorf("provider %s not found in config", provider)
 	}
+	cfg.STTBinary = ExpandPath(cfg.STTBinary)
+	cfg.STTModel = ExpandPath(cfg.STTModel)
 
 	return &cfg, nil
 }
@@ -395,6 +493,14 @@ func GetProviderSTTURL(provider string) (string, error) {
 	return cfg.STTURL, nil
 }
 
+func GetProviderSTTBinary(provider string) (string, error) {
+	cfg, err := GetProviderConfig(provider)
+	if err != nil {
+		return "", err
+	}
+	return cfg.STTBinary, nil
+}
+
 func GetProviderTTSURL(provider string) (string, error) {
 	cfg, err := GetProviderConfig(provider)
 	if err != nil {
@@ -403,6 +509,37 @@ func GetProviderTTSURL(provider string) (string, error) {
 	return cfg.TTSURL, nil
 }
 
+// GetProviderTTSFormat returns the audio format requested from the provider's
+// TTS endpoint. Unset or unsupported values fall back to wav.
+func GetProviderTTSFormat(provider string) string {
+	cfg, err := GetProviderConfig(provider)
+	if err != nil {
+		return defaultTTSFormat
+	}
+	format := strings.ToLower(strings.TrimSpace(cfg.TTSFormat))
+	for _, supported := range SupportedTTSFormats {
+		if format == supported {
+			return format
+		}
+	}
+	return defaultTTSFormat
+}
+
+func GetProviderVoicesURL(provider string) (string, error) {
+	cfg, err := GetProviderConfig(provider)
+	if err != nil {
+		return "", err
+	}
+	return cfg.VoicesURL, nil
+}
+
+func GetVoiceProfiles() []VoiceProfileConfig {
+	if globalConfig != nil {
+		return globalConfig.Voices
+	}
+	return nil
+}
+
 func GetProviderInferenceURL(provider string) (string, error) {
 	cfg, err := GetProviderConfig(provider)
 	if err != nil {
@@ -411,6 +548,92 @@ func GetProviderInferenceURL(provider string) (string, error) {
 	return cfg.InferenceURL, nil
 }
 
+// GetEpisodeConfig returns the episode render settings with defaults applied.
+func GetEpisodeConfig() EpisodeConfig {
+	var cfg EpisodeConfig
+	if globalConfig != nil {
+		cfg = globalConfig.Episode
+	}
+	if cfg.GapMs == 0 {
+		cfg.GapMs = 350
+	}
+	if cfg.SpeakerGapMs == 0 {
+		cfg.SpeakerGapMs = 700
+	}
+	if cfg.LoudnessDBFS == 0 {
+		cfg.LoudnessDBFS = -20
+	}
+	if cfg.PeakDBFS == 0 {
+		cfg.PeakDBFS = -1
+	}
+	if cfg.BedVolumeDB == 0 {
+		cfg.BedVolumeDB = -14
+	}
+	if cfg.BedOverlapMs == 0 {
+		cfg.BedOverlapMs = 2000
+	}
+	cfg.Intro = ExpandPath(cfg.Intro)
+	cfg.Outro = ExpandPath(cfg.Outro)
+	return cfg
+}
+
+// PublishConfig describes the podcast written by vibecast publish.
+type PublishConfig struct {
+	Dir         string `yaml:"dir,omitempty"`      // output directory (default <data_dir>/publish)
+	BaseURL     string `yaml:"base_url,omitempty"` // where the directory will be hosted
+	Title       string `yaml:"title,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Author      string `yaml:"author,omitempty"`
+	Email       string `yaml:"email,omitempty"`
+	Image       string `yaml:"image,omitempty"` // cover art URL
+	Language    string `yaml:"language,omitempty"`
+	Category    string `yaml:"category,omitempty"` // iTunes category
+	Explicit    bool   `yaml:"explicit,omitempty"`
+	Format      string `yaml:"format,omitempty"` // enclosure format: mp3, opus or wav
+}
+
+func GetCacheConfig() CacheConfig {
+	if globalConfig != nil {
+		return globalConfig.Cache
+	}
+	return CacheConfig{}
+}
+
+func GetRetentionConfig() RetentionConfig {
+	if globalConfig != nil {
+		return globalConfig.Retention
+	}
+	return RetentionConfig{}
+}
+
+// GetPublishConfig returns the podcast settings with defaults applied.
+func GetPublishConfig() PublishConfig {
+	var cfg PublishConfig
+	if globalConfig != nil {
+		cfg = globalConfig.Publish
+	}
+	cfg.Dir = ExpandPath(cfg.Dir)
+	if cfg.Dir == "" {
+		cfg.Dir = filepath.Join(GetDataDir(), "publish")
+	}
+	if cfg.Title == "" {
+		cfg.Title = "VibeCast"
+	}
+	if cfg.Description == "" {
+		cfg.Description = "Conversations recorded with VibeCast"
+	}
+	if cfg.Language == "" {
+		cfg.Language = "en"
+	}
+	if cfg.Category == "" {
+		cfg.Category = "Technology"
+	}
+	if cfg.Format == "" {
+		cfg.Format = "mp3"
+	}
+	return cfg
+}
+
 func GetUIConfig() UIConfig {
 	if globalConfig != nil {
 		return globalConfig.UI
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HomeEnvVar overrides the data root, taking precedence over general.data_dir.
+// The default config file also lives there when it is set.
+const HomeEnvVar = "VIBECAST_HOME"
+
+// ExpandPath expands a leading ~ and any $VAR or ${VAR} in a path setting.
+func ExpandPath(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[1:])
+		}
+	}
+	return os.ExpandEnv(path)
+}
+
+// legacyDataDir is where every version before data_dir kept its files.
+func legacyDataDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, defaultConfigDir)
+}
+
+// customDataDir returns the data root chosen with VIBECAST_HOME or
+// general.data_dir, or "" when neither is set.
+func customDataDir() string {
+	if dir := ExpandPath(os.Getenv(HomeEnvVar)); dir != "" {
+		return dir
+	}
+	if globalConfig != nil {
+		return ExpandPath(globalConfig.General.DataDir)
+	}
+	return ""
+}
+
+// hasLegacyData reports whether ~/.vibecast already holds a database or
+// conversations. The config file alone doesn't count.
+func hasLegacyData() bool {
+	for _, name := range []string{defaultDBFile, "conversations"} {
+		if _, err := os.Stat(filepath.Join(legacyDataDir(), name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDataDir returns the root for the database, conversations, caches and
+// published episodes: VIBECAST_HOME, then general.data_dir, then
+// $XDG_DATA_HOME/vibecast, then ~/.vibecast. Existing data in ~/.vibecast wins
+// over XDG so upgrading never hides earlier conversations.
+func GetDataDir() string {
+	if dir := customDataDir(); dir != "" {
+		return dir
+	}
+	if xdg := ExpandPath(os.Getenv("XDG_DATA_HOME")); xdg != "" && !hasLegacyData() {
+		return filepath.Join(xdg, "vibecast")
+	}
+	return legacyDataDir()
+}
+
+// GetLogsDir returns where session logs are written: under a custom data
+// root when one is set, otherwise $XDG_STATE_HOME/vibecast/logs, otherwise
+// the data root.
+func GetLogsDir() string {
+	if dir := customDataDir(); dir != "" {
+		return filepath.Join(dir, "logs")
+	}
+	if xdg := ExpandPath(os.Getenv("XDG_STATE_HOME")); xdg != "" {
+		return filepath.Join(xdg, "vibecast", "logs")
+	}
+	return filepath.Join(GetDataDir(), "logs")
+}
+
+// DefaultConfigPath is used when --config is not given.
+func DefaultConfigPath() string {
+	if dir := ExpandPath(os.Getenv(HomeEnvVar)); dir != "" {
+		return filepath.Join(dir, defaultConfigFile)
+	}
+	return filepath.Join(legacyDataDir(), defaultConfigFile)
+}
@@ -0,0 +1,263 @@
+// Package crypt encrypts conversation data at rest with AES-256-GCM.
+//
+// Once a key is loaded with SetKey, everything written through Seal,
+// SealString and WriteFile is encrypted. Open, OpenString and ReadFile
+// decrypt sealed data and pass anything else through unchanged, so a data
+// directory part way through being encrypted or decrypted still reads.
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nraghuveer/vibecast/lib/atomicfile"
+)
+
+const (
+	// KeySize is the length of an AES-256 key.
+	KeySize = 32
+
+	// fileMagic starts every sealed file.
+	fileMagic = "VIBECAST-ENC1\n"
+	// stringPrefix starts every sealed string: transcript lines and
+	// database values.
+	stringPrefix = "enc1:"
+)
+
+// ErrLocked is returned when sealed data is read without a key.
+var ErrLocked = errors.New("data is encrypted and no key is loaded")
+
+// Key seals and opens data.
+type Key struct {
+	aead cipher.AEAD
+}
+
+// NewKey makes a key from KeySize raw bytes.
+func NewKey(raw []byte) (*Key, error) {
+	if len(raw) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes", KeySize)
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return &Key{aead: aead}, nil
+}
+
+// seal returns a random nonce followed by the ciphertext.
+func (k *Key) seal(plaintext []byte) []byte {
+	nonce := make([]byte, k.aead.NonceSize(), k.aead.NonceSize()+len(plaintext)+k.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		panic(fmt.Sprintf("crypt: failed to read random nonce: %v", err))
+	}
+	return k.aead.Seal(nonce, nonce, plaintext, nil)
+}
+
+func (k *Key) open(data []byte) ([]byte, error) {
+	n := k.aead.NonceSize()
+	if len(data) < n+k.aead.Overhead() {
+		return nil, errors.New("failed to decrypt: data is too short")
+	}
+	plaintext, err := k.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt: wrong key or damaged data")
+	}
+	return plaintext, nil
+}
+
+var (
+	mu     sync.RWMutex
+	active *Key
+)
+
+// SetKey loads the key used to seal new data and open sealed data. A nil
+// key stops sealing; sealed data then can't be read.
+func SetKey(k *Key) {
+	mu.Lock()
+	defer mu.Unlock()
+	active = k
+}
+
+// Enabled reports whether a key is loaded, so new data is sealed.
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active != nil
+}
+
+func activeKey() *Key {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active
+}
+
+// IsSealed reports whether data is a sealed file.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(fileMagic))
+}
+
+// Seal encrypts file contents with the loaded key. Without a key, or if
+// data is already sealed, data is returned unchanged.
+func Seal(data []byte) []byte {
+	k := activeKey()
+	if k == nil || IsSealed(data) {
+		return data
+	}
+	return append([]byte(fileMagic), k.seal(data)...)
+}
+
+// Open decrypts sealed file contents; anything else is returned unchanged.
+func Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+	k := activeKey()
+	if k == nil {
+		return nil, ErrLocked
+	}
+	return k.open(data[len(fileMagic):])
+}
+
+// IsSealedString reports whether s is a sealed string.
+func IsSealedString(s string) bool {
+	return strings.HasPrefix(s, stringPrefix)
+}
+
+// SealString encrypts s with the loaded key as printable text. Without a
+// key, or if s is empty or already sealed, s is returned unchanged.
+func SealString(s string) string {
+	k := activeKey()
+	if k == nil || s == "" || IsSealedString(s) {
+		return s
+	}
+	return stringPrefix + base64.RawStdEncoding.EncodeToString(k.seal([]byte(s)))
+}
+
+// OpenString decrypts a sealed string; anything else is returned unchanged.
+func OpenString(s string) (string, error) {
+	if !IsSealedString(s) {
+		return s, nil
+	}
+	k := activeKey()
+	if k == nil {
+		return "", ErrLocked
+	}
+	data, err := base64.RawStdEncoding.DecodeString(s[len(stringPrefix):])
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+	plaintext, err := k.open(data)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// ReadFile reads a file, decrypting it if it is sealed.
+func ReadFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Open(data)
+}
+
+// WriteFile writes data to path, sealed when a key is loaded.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(path, Seal(data), perm)
+}
+
+// IsSealedFile reports whether the file at path is sealed.
+func IsSealedFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	head := make([]byte, len(fileMagic))
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return IsSealed(head[:n]), nil
+}
+
+// PlainFile returns a path holding the plaintext of the file at path, for
+// tools that read files themselves. Unsealed files are returned as is;
+// sealed ones are decrypted to a private temp file that cleanup removes.
+func PlainFile(path string) (plainPath string, cleanup func(), err error) {
+	sealed, err := IsSealedFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if !sealed {
+		return path, func() {}, nil
+	}
+
+	data, err := ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	f, err := os.CreateTemp("", "vibecast-*"+filepath.Ext(path))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	cleanup = func() { os.Remove(f.Name()) }
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return f.Name(), cleanup, nil
+}
+
+// ResealFile rewrites the file at path sealed with the loaded key when seal
+// is set, otherwise decrypted, replacing it through a temp file and keeping
+// its modification time. It reports whether the file changed; files already
+// in the wanted form are left alone.
+func ResealFile(path string, seal bool) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	if IsSealed(data) == seal {
+		return false, nil
+	}
+	if seal {
+		if !Enabled() {
+			return false, ErrLocked
+		}
+		data = Seal(data)
+	} else if data, err = Open(data); err != nil {
+		return false, fmt.Errorf("failed to decrypt %s: %w", filepath.Base(path), err)
+	}
+
+	if err := atomicfile.Write(path, data, info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	os.Chtimes(path, info.ModTime(), info.ModTime())
+	return true, nil
+}
@@ -0,0 +1,295 @@
+package crypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useKey loads a fresh random key for the test and unloads it afterwards.
+func useKey(t *testing.T) *Key {
+	t.Helper()
+	k := newTestKey(t)
+	SetKey(k)
+	t.Cleanup(func() { SetKey(nil) })
+	return k
+}
+
+func newTestKey(t *testing.T) *Key {
+	t.Helper()
+	raw := make([]byte, KeySize)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewKey(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	useKey(t)
+
+	plain := []byte("the guest said something private")
+	sealed := Seal(plain)
+	if !IsSealed(sealed) || bytes.Contains(sealed, plain) {
+		t.Fatalf("Seal left the data readable: %q", sealed)
+	}
+	if again := Seal(sealed); !bytes.Equal(again, sealed) {
+		t.Error("Seal sealed already sealed data again")
+	}
+	opened, err := Open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plain) {
+		t.Errorf("Open returned %q, want %q", opened, plain)
+	}
+
+	s := SealString("a topic")
+	if !IsSealedString(s) {
+		t.Fatalf("SealString left %q readable", s)
+	}
+	if got, err := OpenString(s); err != nil || got != "a topic" {
+		t.Errorf("OpenString returned %q, %v", got, err)
+	}
+	if SealString("") != "" {
+		t.Error("SealString sealed an empty string")
+	}
+}
+
+func TestPlainDataPassesThrough(t *testing.T) {
+	plain := []byte("not sealed")
+	if got := Seal(plain); !bytes.Equal(got, plain) {
+		t.Error("Seal changed data without a key")
+	}
+	if got, err := Open(plain); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("Open changed plain data: %q, %v", got, err)
+	}
+
+	useKey(t)
+	if got, err := Open(plain); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("Open changed plain data with a key loaded: %q, %v", got, err)
+	}
+	if got, err := OpenString("plain"); err != nil || got != "plain" {
+		t.Errorf("OpenString changed a plain string: %q, %v", got, err)
+	}
+}
+
+func TestOpenWithoutKeyIsLocked(t *testing.T) {
+	useKey(t)
+	sealed := Seal([]byte("secret"))
+	s := SealString("secret")
+	SetKey(nil)
+
+	if _, err := Open(sealed); !errors.Is(err, ErrLocked) {
+		t.Errorf("Open without a key returned %v, want ErrLocked", err)
+	}
+	if _, err := OpenString(s); !errors.Is(err, ErrLocked) {
+		t.Errorf("OpenString without a key returned %v, want ErrLocked", err)
+	}
+}
+
+func TestOpenWithWrongKeyFails(t *testing.T) {
+	useKey(t)
+	sealed := Seal([]byte("secret"))
+
+	raw := make([]byte, KeySize)
+	other, err := NewKey(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetKey(other)
+	if _, err := Open(sealed); err == nil {
+		t.Error("Open succeeded with the wrong key")
+	}
+}
+
+func TestOpenRejectsTampering(t *testing.T) {
+	useKey(t)
+
+	sealed := Seal([]byte("secret"))
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := Open(tampered); err == nil {
+		t.Error("Open accepted a modified file")
+	}
+	if _, err := Open(sealed[:len(fileMagic)+4]); err == nil {
+		t.Error("Open accepted a truncated file")
+	}
+
+	s := []byte(SealString("secret"))
+	s[len(s)-2] ^= 1
+	if got, err := OpenString(string(s)); err == nil {
+		t.Errorf("OpenString accepted a modified string: %q", got)
+	}
+}
+
+func TestDeriveChecksSecret(t *testing.T) {
+	for _, kdf := range []string{KDFPassphrase, KDFKeyfile} {
+		t.Run(kdf, func(t *testing.T) {
+			p, err := NewParams(kdf, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if kdf == KDFPassphrase {
+				p.Iterations = 1000 // keep the test fast
+			}
+
+			k, err := p.Derive([]byte("correct horse"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.SetCheck(k)
+
+			again, err := p.Derive([]byte("correct horse"))
+			if err != nil {
+				t.Fatalf("Derive refused the right secret: %v", err)
+			}
+			SetKey(k)
+			sealed := Seal([]byte("data"))
+			SetKey(again)
+			if _, err := Open(sealed); err != nil {
+				t.Errorf("a re-derived key couldn't open data: %v", err)
+			}
+			SetKey(nil)
+
+			if _, err := p.Derive([]byte("wrong horse")); err == nil {
+				t.Error("Derive accepted the wrong secret")
+			}
+			if _, err := p.Derive(nil); err == nil {
+				t.Error("Derive accepted an empty secret")
+			}
+		})
+	}
+}
+
+func TestParamsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if p, err := LoadParams(dir); err != nil || p != nil {
+		t.Fatalf("LoadParams on an unencrypted directory returned %v, %v", p, err)
+	}
+
+	p, err := NewParams(KDFKeyfile, "/keys/vibecast.key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := p.Derive([]byte("keyfile contents"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetCheck(k)
+	if err := SaveParams(dir, p); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadParams(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loaded.Derive([]byte("keyfile contents")); err != nil {
+		t.Errorf("loaded params refused the right secret: %v", err)
+	}
+	if _, err := loaded.Derive([]byte("other contents")); err == nil {
+		t.Error("loaded params accepted the wrong secret")
+	}
+
+	if err := RemoveParams(dir); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := LoadParams(dir); err != nil || p != nil {
+		t.Errorf("LoadParams after RemoveParams returned %v, %v", p, err)
+	}
+}
+
+func TestResealFileIsIdempotent(t *testing.T) {
+	useKey(t)
+
+	path := filepath.Join(t.TempDir(), "001.wav")
+	plain := []byte("RIFF....WAVE")
+	if err := os.WriteFile(path, plain, 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct {
+		seal    bool
+		changed bool
+	}{
+		{true, true},
+		{true, false},
+		{false, true},
+		{false, false},
+	} {
+		changed, err := ResealFile(path, step.seal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != step.changed {
+			t.Errorf("ResealFile(seal=%v) changed=%v, want %v", step.seal, changed, step.changed)
+		}
+		sealed, err := IsSealedFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sealed != step.seal {
+			t.Errorf("after ResealFile(seal=%v) the file is sealed=%v", step.seal, sealed)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.ModTime().Equal(mtime) {
+			t.Errorf("ResealFile changed the modification time to %v", info.ModTime())
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, plain) {
+		t.Errorf("after sealing and opening the file holds %q, want %q", data, plain)
+	}
+}
+
+func TestResealFileNeedsKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "001.wav")
+	if err := os.WriteFile(path, []byte("RIFF"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ResealFile(path, true); !errors.Is(err, ErrLocked) {
+		t.Errorf("ResealFile without a key returned %v, want ErrLocked", err)
+	}
+}
+
+func TestPlainFile(t *testing.T) {
+	useKey(t)
+
+	path := filepath.Join(t.TempDir(), "episode.wav")
+	if err := WriteFile(path, []byte("RIFF"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plainPath, cleanup, err := PlainFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plainPath == path {
+		t.Fatal("PlainFile returned the sealed file itself")
+	}
+	if data, err := os.ReadFile(plainPath); err != nil || string(data) != "RIFF" {
+		t.Errorf("PlainFile wrote %q, %v", data, err)
+	}
+	cleanup()
+	if _, err := os.Stat(plainPath); !os.IsNotExist(err) {
+		t.Error("cleanup left the temp file behind")
+	}
+}
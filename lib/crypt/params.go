@@ -0,0 +1,166 @@
+package crypt
+
+import (
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nraghuveer/vibecast/lib/atomicfile"
+)
+
+// ParamsFileName is the file in the data directory that marks it as
+// encrypted and records how to derive its key. It holds no secrets.
+const ParamsFileName = "encryption.json"
+
+// Key derivation functions.
+const (
+	KDFPassphrase = "pbkdf2-sha256" // from a passphrase
+	KDFKeyfile    = "hkdf-sha256"   // from the contents of a keyfile
+)
+
+const (
+	paramsVersion        = 1
+	passphraseIterations = 600000
+	saltSize             = 16
+	keyInfo              = "vibecast data key"
+	checkPlaintext       = "vibecast"
+)
+
+// Params describes an encrypted data directory's key.
+type Params struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt"`
+	Iterations int    `json:"iterations,omitempty"` // passphrase only
+	Keyfile    string `json:"keyfile,omitempty"`    // where the keyfile was when the directory was encrypted
+	Check      []byte `json:"check"`                // a known value sealed with the key, to catch a wrong one
+}
+
+// NewParams returns fresh parameters for kdf with a random salt.
+func NewParams(kdf, keyfile string) (Params, error) {
+	p := Params{Version: paramsVersion, KDF: kdf, Salt: make([]byte, saltSize)}
+	switch kdf {
+	case KDFPassphrase:
+		p.Iterations = passphraseIterations
+	case KDFKeyfile:
+		p.Keyfile = keyfile
+	default:
+		return p, fmt.Errorf("unknown key derivation %q", kdf)
+	}
+	if _, err := rand.Read(p.Salt); err != nil {
+		return p, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return p, nil
+}
+
+// Derive makes the key from a passphrase or keyfile contents. Once the
+// parameters have a check value, a secret that doesn't match it is refused.
+func (p *Params) Derive(secret []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("encryption passphrase or keyfile is empty")
+	}
+
+	var raw []byte
+	var err error
+	switch p.KDF {
+	case KDFPassphrase:
+		raw, err = pbkdf2.Key(sha256.New, string(secret), p.Salt, p.Iterations, KeySize)
+	case KDFKeyfile:
+		raw, err = hkdf.Key(sha256.New, secret, p.Salt, keyInfo, KeySize)
+	default:
+		return nil, fmt.Errorf("unknown key derivation %q", p.KDF)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	k, err := NewKey(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Check) == 0 {
+		return k, nil
+	}
+	if _, err := k.open(p.Check); err != nil {
+		return nil, errors.New("wrong encryption passphrase or keyfile")
+	}
+	return k, nil
+}
+
+// SetCheck records a value sealed with k so Derive can verify the secret.
+func (p *Params) SetCheck(k *Key) {
+	p.Check = k.seal([]byte(checkPlaintext))
+}
+
+// LoadParams reads dataDir's encryption parameters. It returns nil when the
+// directory isn't encrypted.
+func LoadParams(dataDir string) (*Params, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, ParamsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read encryption settings: %w", err)
+	}
+
+	var p Params
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse encryption settings: %w", err)
+	}
+	if p.Version > paramsVersion {
+		return nil, fmt.Errorf("encryption settings are version %d; this build reads up to %d", p.Version, paramsVersion)
+	}
+	return &p, nil
+}
+
+// SaveParams writes dataDir's encryption parameters.
+func SaveParams(dataDir string, p Params) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode encryption settings: %w", err)
+	}
+	path := filepath.Join(dataDir, ParamsFileName)
+	if err := atomicfile.Write(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write encryption settings: %w", err)
+	}
+	return nil
+}
+
+// RemoveParams marks dataDir as no longer encrypted.
+func RemoveParams(dataDir string) error {
+	if err := os.Remove(filepath.Join(dataDir, ParamsFileName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove encryption settings: %w", err)
+	}
+	return nil
+}
+
+// NewKeyfile writes KeySize random bytes to path, readable only by the
+// owner. It fails rather than replace an existing file.
+func NewKeyfile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create keyfile: %w", err)
+	}
+	raw := make([]byte, KeySize)
+	if _, err := rand.Read(raw); err != nil {
+		f.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	if _, err := f.Write(raw); err != nil {
+		f.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write keyfile: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to write keyfile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/models"
+)
+
+// NextAudioIndex returns the sequence number for the next clip of a conversation
+func (db *DB) NextAudioIndex(conversationID string) (int, error) {
+	query := `SELECT COALESCE(MAX(idx), 0) + 1 FROM audio_files WHERE conversation_id = ?`
+
+	var index int
+	if err := db.QueryRow(query, conversationID).Scan(&index); err != nil {
+		return 0, fmt.Errorf("failed to get next audio index: %w", err)
+	}
+
+	return index, nil
+}
+
+// RecordAudioFile indexes a clip; re-recording the same filename updates it
+func (db *DB) RecordAudioFile(a models.AudioFile) error {
+	query := `
+		INSERT INTO audio_files (conversation_id, idx, filename, speaker, message_id, format, duration_ms, sample_rate, channels, size_bytes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(conversation_id, filename) DO UPDATE SET
+			idx = excluded.idx,
+			speaker = excluded.speaker,
+			message_id = CASE WHEN excluded.message_id = '' THEN audio_files.message_id ELSE excluded.message_id END,
+			format = excluded.format,
+			duration_ms = excluded.duration_ms,
+			sample_rate = excluded.sample_rate,
+			channels = excluded.channels,
+			size_bytes = excluded.size_bytes
+	`
+
+	_, err := db.Exec(query,
+		a.ConversationID,
+		a.Index,
+		a.Filename,
+		a.Speaker.String(),
+		a.MessageID,
+		a.Format,
+		a.Duration.Milliseconds(),
+		a.SampleRate,
+		a.Channels,
+		a.SizeBytes,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audio file: %w", err)
+	}
+
+	return nil
+}
+
+// GetAudioFiles returns a conversation's clips in playback order
+func (db *DB) GetAudioFiles(conversationID string) ([]models.AudioFile, error) {
+	query := `
+		SELECT conversation_id, idx, filename, speaker, message_id, format, duration_ms, sample_rate, channels, size_bytes, created_at
+		FROM audio_files
+		WHERE conversation_id = ?
+		ORDER BY idx
+	`
+
+	rows, err := db.Query(query, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audio files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []models.AudioFile
+	for rows.Next() {
+		var a models.AudioFile
+		var speaker string
+		var durationMs int64
+		err := rows.Scan(
+			&a.ConversationID,
+			&a.Index,
+			&a.Filename,
+			&speaker,
+			&a.MessageID,
+			&a.Format,
+			&durationMs,
+			&a.SampleRate,
+			&a.Channels,
+			&a.SizeBytes,
+			&a.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audio file: %w", err)
+		}
+		a.Speaker = models.ParseSpeakerType(speaker)
+		a.Duration = time.Duration(durationMs) * time.Millisecond
+		files = append(files, a)
+	}
+
+	return files, nil
+}
+
+// LinkAudioFile records which transcript message a clip voices
+func (db *DB) LinkAudioFile(conversationID, filename, messageID string) error {
+	query := `UPDATE audio_files SET message_id = ? WHERE conversation_id = ? AND filename = ?`
+
+	if _, err := db.Exec(query, messageID, conversationID, filename); err != nil {
+		return fmt.Errorf("failed to link audio file: %w", err)
+	}
+
+	return nil
+}
+
+// CountAudioFiles returns how many clips are indexed for a conversation
+func (db *DB) CountAudioFiles(conversationID string) (int, error) {
+	query := `SELECT COUNT(*) FROM audio_files WHERE conversation_id = ?`
+
+	var count int
+	if err := db.QueryRow(query, conversationID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count audio files: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteAudioFileRecord removes a clip from the index
+func (db *DB) DeleteAudioFileRecord(conversationID, filename string) error {
+	query := `DELETE FROM audio_files WHERE conversation_id = ? AND filename = ?`
+
+	if _, err := db.Exec(query, conversationID, filename); err != nil {
+		return fmt.Errorf("failed to delete audio file record: %w", err)
+	}
+
+	return nil
+}
@@ -5,28 +5,59 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/nraghuveer/vibecast/lib/crypt"
 	"github.com/nraghuveer/vibecast/lib/models"
 )
 
 type Conversation struct {
-	ID        string
-	Title     string
-	Topic     string
-	Persona   string
-	VoiceID   string
-	VoiceName string
-	Provider  string
-	CreatedAt time.Time
-	EndedAt   sql.NullTime
+	ID                 string
+	Title              string
+	Topic              string
+	Persona            string
+	VoiceID            string
+	VoiceName          string
+	VoiceProfileID     string
+	HostVoiceProfileID string
+	Provider           string
+	CreatedAt          time.Time
+	EndedAt            sql.NullTime
+	ArchivedAt         sql.NullTime
+	StarredAt          sql.NullTime
+	AudioPrunedAt      sql.NullTime // set once retention removes any of its clips
+
+	ParentID            string // conversation this was forked from; "" if none
+	ForkedFromMessageID string // last message copied from the parent
+}
+
+// open decrypts the columns stored sealed.
+func (c *Conversation) open() error {
+	var err error
+	if c.Topic, err = crypt.OpenString(c.Topic); err != nil {
+		return fmt.Errorf("failed to read conversation topic: %w", err)
+	}
+	if c.Persona, err = crypt.OpenString(c.Persona); err != nil {
+		return fmt.Errorf("failed to read conversation persona: %w", err)
+	}
+	return nil
 }
 
 func (db *DB) CreateConversation(c models.Conversation) error {
 	query := `
-		INSERT INTO conversations (id, title, topic, persona, voice_id, voice_name, provider, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO conversations (id, title, topic, persona, voice_id, voice_name, voice_profile_id, host_voice_profile_id, provider, created_at, parent_id, forked_from_message_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := db.Exec(query, c.ID, c.Title, c.Topic, c.Persona, c.VoiceID, c.VoiceName, c.Provider, c.CreatedAt)
+	var voiceProfileID sql.NullString
+	if c.VoiceProfileID != "" {
+		voiceProfileID = sql.NullString{String: c.VoiceProfileID, Valid: true}
+	}
+
+	var parentID sql.NullString
+	if c.ParentID != "" {
+		parentID = sql.NullString{String: c.ParentID, Valid: true}
+	}
+
+	_, err := db.Exec(query, c.ID, c.Title, crypt.SealString(c.Topic), crypt.SealString(c.Persona), c.VoiceID, c.VoiceName, voiceProfileID, c.HostVoiceProfileID, c.Provider, c.CreatedAt, parentID, c.ForkedFromMessageID)
 	if err != nil {
 		return fmt.Errorf("failed to create conversation: %w", err)
 	}
@@ -36,7 +67,7 @@ func (db *DB) CreateConversation(c models.Conversation) error {
 
 func (db *DB) GetConversation(id string) (*Conversation, error) {
 	query := `
-		SELECT id, title, topic, persona, voice_id, voice_name, provider, created_at, ended_at
+		SELECT id, title, topic, persona, voice_id, voice_name, COALESCE(voice_profile_id, ''), host_voice_profile_id, provider, created_at, ended_at, archived_at, starred_at, audio_pruned_at, COALESCE(parent_id, ''), forked_from_message_id
 		FROM conversations
 		WHERE id = ?
 	`
@@ -49,9 +80,16 @@ func (db *DB) GetConversation(id string) (*Conversation, error) {
 		&c.Persona,
 		&c.VoiceID,
 		&c.VoiceName,
+		&c.VoiceProfileID,
+		&c.HostVoiceProfileID,
 		&c.Provider,
 		&c.CreatedAt,
 		&c.EndedAt,
+		&c.ArchivedAt,
+		&c.StarredAt,
+		&c.AudioPrunedAt,
+		&c.ParentID,
+		&c.ForkedFromMessageID,
 	)
 
 	if err != nil {
@@ -60,13 +98,16 @@ func (db *DB) GetConversation(id string) (*Conversation, error) {
 		}
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
+	if err := c.open(); err != nil {
+		return nil, err
+	}
 
 	return &c, nil
 }
 
 func (db *DB) GetAllConversations() ([]Conversation, error) {
 	query := `
-		SELECT id, title, topic, persona, voice_id, voice_name, provider, created_at, ended_at
+		SELECT id, title, topic, persona, voice_id, voice_name, COALESCE(voice_profile_id, ''), host_voice_profile_id, provider, created_at, ended_at, archived_at, starred_at, audio_pruned_at, COALESCE(parent_id, ''), forked_from_message_id
 		FROM conversations
 		ORDER BY created_at DESC
 	`
@@ -87,13 +128,23 @@ func (db *DB) GetAllConversations() ([]Conversation, error) {
 			&c.Persona,
 			&c.VoiceID,
 			&c.VoiceName,
+			&c.VoiceProfileID,
+			&c.HostVoiceProfileID,
 			&c.Provider,
 			&c.CreatedAt,
 			&c.EndedAt,
+			&c.ArchivedAt,
+			&c.StarredAt,
+			&c.AudioPrunedAt,
+			&c.ParentID,
+			&c.ForkedFromMessageID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan conversation: %w", err)
 		}
+		if err := c.open(); err != nil {
+			return nil, err
+		}
 		conversations = append(conversations, c)
 	}
 
@@ -124,6 +175,93 @@ func (db *DB) UpdateConversationEndedAt(id string, endedAt time.Time) error {
 	return nil
 }
 
+// RenameConversation sets a conversation's title
+func (db *DB) RenameConversation(id, title string) error {
+	result, err := db.Exec(`UPDATE conversations SET title = ? WHERE id = ?`, title, id)
+	if err != nil {
+		return fmt.Errorf("failed to rename conversation: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("conversation not found")
+	}
+
+	return nil
+}
+
+// SetConversationArchived archives a conversation, or restores it to the list
+func (db *DB) SetConversationArchived(id string, archived bool) error {
+	var archivedAt sql.NullTime
+	if archived {
+		archivedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	result, err := db.Exec(`UPDATE conversations SET archived_at = ? WHERE id = ?`, archivedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update conversation archived_at: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("conversation not found")
+	}
+
+	return nil
+}
+
+// SetConversationStarred stars a conversation, or unstars it
+func (db *DB) SetConversationStarred(id string, starred bool) error {
+	var starredAt sql.NullTime
+	if starred {
+		starredAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	result, err := db.Exec(`UPDATE conversations SET starred_at = ? WHERE id = ?`, starredAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update conversation starred_at: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("conversation not found")
+	}
+
+	return nil
+}
+
+// MarkAudioPruned records that retention removed clips from a conversation.
+// The first time is kept.
+func (db *DB) MarkAudioPruned(id string, prunedAt time.Time) error {
+	result, err := db.Exec(`UPDATE conversations SET audio_pruned_at = COALESCE(audio_pruned_at, ?) WHERE id = ?`, prunedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to update conversation audio_pruned_at: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("conversation not found")
+	}
+
+	return nil
+}
+
 func (db *DB) DeleteConversation(id string) error {
 	query := `DELETE FROM conversations WHERE id = ?`
 
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/nraghuveer/vibecast/lib/crypt"
+)
+
+// sealedColumns are the columns stored sealed when encryption is on.
+var sealedColumns = []struct {
+	table  string
+	key    string
+	column string
+}{
+	{"conversations", "id", "topic"},
+	{"conversations", "id", "persona"},
+	{"templates", "id", "topic"},
+	{"templates", "id", "persona"},
+	{"messages", "id", "content"},
+}
+
+// ResealColumns rewrites every sealed column: encrypted with the loaded key
+// when seal is set, otherwise in the clear. Values already in the wanted
+// form are left alone, so an interrupted run can simply be repeated. It
+// returns how many values were rewritten.
+func (db *DB) ResealColumns(seal bool) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	changed := 0
+	for _, col := range sealedColumns {
+		rows, err := tx.Query(fmt.Sprintf(`SELECT %s, %s FROM %s`, col.key, col.column, col.table))
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", col.table, err)
+		}
+		updates := map[string]string{}
+		for rows.Next() {
+			var key, value string
+			if err := rows.Scan(&key, &value); err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("failed to read %s: %w", col.table, err)
+			}
+			if value == "" || crypt.IsSealedString(value) == seal {
+				continue
+			}
+			plain, err := crypt.OpenString(value)
+			if err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("failed to read %s.%s: %w", col.table, col.column, err)
+			}
+			if seal {
+				updates[key] = crypt.SealString(plain)
+			} else {
+				updates[key] = plain
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", col.table, err)
+		}
+
+		update := fmt.Sprintf(`UPDATE %s SET %s = ? WHERE %s = ?`, col.table, col.column, col.key)
+		for key, value := range updates {
+			if _, err := tx.Exec(update, value, key); err != nil {
+				return 0, fmt.Errorf("failed to update %s: %w", col.table, err)
+			}
+			changed++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+
+	// Rebuild the search index and compact the file so the old values
+	// don't linger in index segments or free pages.
+	if db.fts {
+		if _, err := db.Exec(`INSERT INTO messages_fts(messages_fts) VALUES ('rebuild')`); err != nil {
+			return changed, fmt.Errorf("failed to rebuild message search index: %w", err)
+		}
+	}
+	if _, err := db.Exec(`VACUUM`); err != nil {
+		return changed, fmt.Errorf("failed to compact database: %w", err)
+	}
+	return changed, nil
+}
@@ -13,6 +13,7 @@ import (
 // DB wraps sql.DB and provides database operations
 type DB struct {
 	*sql.DB
+	fts bool // messages_fts is available for search
 }
 
 // NewDB creates and initializes a new database instance
@@ -39,12 +40,118 @@ func NewDB() (*DB, error) {
 	return db, nil
 }
 
+// schemaMigrations lists the schema files applied on top of v0, in order.
+// Index i holds the file that upgrades the database to user_version i+1.
+var schemaMigrations = []string{
+	"schema/v1.sql",
+	"schema/v2.sql",
+	"schema/v3.sql",
+	"schema/v4.sql",
+	"schema/v5.sql",
+	"schema/v6.sql",
+	"schema/v7.sql",
+	"schema/v8.sql",
+	"schema/v9.sql",
+	"schema/v10.sql",
+	"schema/v11.sql",
+	"schema/v12.sql",
+}
+
+// ftsSchema holds the full-text index over messages. It isn't a numbered
+// migration because it needs SQLite built with FTS5.
+const ftsSchema = "schema/fts.sql"
+
+// ftsTriggers keep messages_fts in sync with messages.
+var ftsTriggers = []string{"messages_fts_insert", "messages_fts_delete", "messages_fts_update"}
+
 func (db *DB) createTables() error {
 	schemaSQL, err := os.ReadFile("schema/v0.sql")
 	if err != nil {
 		return fmt.Errorf("failed to read schema file: %w", err)
 	}
 
-	_, err = db.Exec(string(schemaSQL))
-	return err
+	if _, err := db.Exec(string(schemaSQL)); err != nil {
+		return err
+	}
+
+	if err := db.migrate(); err != nil {
+		return err
+	}
+
+	return db.enableSearch()
+}
+
+// migrate applies every schema migration newer than the database's
+// user_version. Each migration runs in its own transaction.
+func (db *DB) migrate() error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for i := version; i < len(schemaMigrations); i++ {
+		migrationSQL, err := os.ReadFile(schemaMigrations[i])
+		if err != nil {
+			return fmt.Errorf("failed to read schema file: %w", err)
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration: %w", err)
+		}
+		if _, err := tx.Exec(string(migrationSQL)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply %s: %w", schemaMigrations[i], err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to update schema version: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// enableSearch creates the full-text index when SQLite supports FTS5. The
+// index is rebuilt whenever its triggers were missing, since messages may
+// have been written meanwhile. Without FTS5 the triggers are dropped, or
+// every insert into messages would fail.
+func (db *DB) enableSearch() error {
+	var available bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&available); err != nil {
+		return fmt.Errorf("failed to check for FTS5: %w", err)
+	}
+
+	if !available {
+		for _, trigger := range ftsTriggers {
+			if _, err := db.Exec("DROP TRIGGER IF EXISTS " + trigger); err != nil {
+				return fmt.Errorf("failed to drop search trigger: %w", err)
+			}
+		}
+		return nil
+	}
+
+	var triggers int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'messages_fts_%'`).Scan(&triggers); err != nil {
+		return fmt.Errorf("failed to check search index: %w", err)
+	}
+
+	ftsSQL, err := os.ReadFile(ftsSchema)
+	if err != nil {
+		return fmt.Errorf("failed to read schema file: %w", err)
+	}
+	if _, err := db.Exec(string(ftsSQL)); err != nil {
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+	if triggers < len(ftsTriggers) {
+		if _, err := db.Exec(`INSERT INTO messages_fts(messages_fts) VALUES ('rebuild')`); err != nil {
+			return fmt.Errorf("failed to rebuild search index: %w", err)
+		}
+	}
+
+	db.fts = true
+	return nil
 }
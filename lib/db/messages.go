@@ -0,0 +1,292 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nraghuveer/vibecast/lib/crypt"
+	"github.com/nraghuveer/vibecast/lib/models"
+)
+
+// snippetRunes is roughly how much context the substring fallback shows on
+// each side of a match, close to FTS5's 12-token snippets.
+const snippetRunes = 40
+
+// RecordMessage indexes a transcript message; recording it again is a no-op
+func (db *DB) RecordMessage(m models.Message) error {
+	query := `
+		INSERT INTO messages (message_id, conversation_id, speaker, content, created_at, take_of, alternate)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(message_id) DO NOTHING
+	`
+
+	_, err := db.Exec(query, m.ID, m.ConversationID, m.Speaker.String(), crypt.SealString(m.Content), m.CreatedAt, m.TakeOf, m.Alternate)
+	if err != nil {
+		return fmt.Errorf("failed to record message: %w", err)
+	}
+
+	return nil
+}
+
+// SetMessageAlternate marks an indexed take as an alternate or the canonical one
+func (db *DB) SetMessageAlternate(messageID string, alternate bool) error {
+	if _, err := db.Exec(`UPDATE messages SET alternate = ? WHERE message_id = ?`, alternate, messageID); err != nil {
+		return fmt.Errorf("failed to update message: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMessage removes a message from the index
+func (db *DB) DeleteMessage(messageID string) error {
+	if _, err := db.Exec(`DELETE FROM messages WHERE message_id = ?`, messageID); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+
+	return nil
+}
+
+// CountMessages returns how many messages are indexed for a conversation
+func (db *DB) CountMessages(conversationID string) (int, error) {
+	query := `SELECT COUNT(*) FROM messages WHERE conversation_id = ?`
+
+	var count int
+	if err := db.QueryRow(query, conversationID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count messages: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetMessages returns a conversation's indexed messages in the order they
+// were recorded
+func (db *DB) GetMessages(conversationID string) ([]models.Message, error) {
+	query := `
+		SELECT message_id, conversation_id, speaker, content, created_at, take_of, alternate
+		FROM messages
+		WHERE conversation_id = ?
+		ORDER BY created_at, id
+	`
+
+	rows, err := db.Query(query, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []models.Message
+	for rows.Next() {
+		var m models.Message
+		var speaker string
+		if err := rows.Scan(&m.ID, &m.ConversationID, &speaker, &m.Content, &m.CreatedAt, &m.TakeOf, &m.Alternate); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if m.Content, err = crypt.OpenString(m.Content); err != nil {
+			return nil, fmt.Errorf("failed to read message: %w", err)
+		}
+		m.Speaker = models.ParseSpeakerType(speaker)
+		messages = append(messages, m)
+	}
+
+	return messages, nil
+}
+
+// SearchMessages finds messages containing every word of query, the last
+// word as a prefix. Only canonical takes match. With FTS5 results are ranked by relevance, otherwise
+// newest first. When encryption is on, content is sealed, so neither the
+// index nor LIKE can see it and messages are decrypted and matched here.
+func (db *DB) SearchMessages(query string, limit int) ([]models.MessageMatch, error) {
+	terms := strings.Fields(strings.ReplaceAll(query, `"`, " "))
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	if crypt.Enabled() {
+		return db.searchSealed(terms, limit)
+	}
+	if db.fts {
+		return db.searchFTS(terms, limit)
+	}
+	return db.searchLike(terms, limit)
+}
+
+func (db *DB) searchFTS(terms []string, limit int) ([]models.MessageMatch, error) {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + term + `"`
+	}
+	quoted[len(quoted)-1] += "*"
+
+	query := `
+		SELECT m.message_id, m.conversation_id, m.speaker, m.content, m.created_at, c.title,
+			snippet(messages_fts, 0, '', '', '…', 12)
+		FROM messages_fts
+		JOIN messages m ON m.id = messages_fts.rowid
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE messages_fts MATCH ? AND m.alternate = 0
+		ORDER BY rank
+		LIMIT ?
+	`
+
+	rows, err := db.Query(query, strings.Join(quoted, " "), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.MessageMatch
+	for rows.Next() {
+		var match models.MessageMatch
+		var speaker string
+		err := rows.Scan(
+			&match.ID,
+			&match.ConversationID,
+			&speaker,
+			&match.Content,
+			&match.CreatedAt,
+			&match.ConversationTitle,
+			&match.Snippet,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		match.Speaker = models.ParseSpeakerType(speaker)
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+func (db *DB) searchLike(terms []string, limit int) ([]models.MessageMatch, error) {
+	var where []string
+	var args []any
+	for _, term := range terms {
+		where = append(where, `m.content LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(term)+"%")
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT m.message_id, m.conversation_id, m.speaker, m.content, m.created_at, c.title
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE m.alternate = 0 AND ` + strings.Join(where, " AND ") + `
+		ORDER BY m.created_at DESC
+		LIMIT ?
+	`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.MessageMatch
+	for rows.Next() {
+		var match models.MessageMatch
+		var speaker string
+		err := rows.Scan(
+			&match.ID,
+			&match.ConversationID,
+			&speaker,
+			&match.Content,
+			&match.CreatedAt,
+			&match.ConversationTitle,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		match.Speaker = models.ParseSpeakerType(speaker)
+		match.Snippet = snippet(match.Content, terms[0])
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+func (db *DB) searchSealed(terms []string, limit int) ([]models.MessageMatch, error) {
+	query := `
+		SELECT m.message_id, m.conversation_id, m.speaker, m.content, m.created_at, c.title
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE m.alternate = 0
+		ORDER BY m.created_at DESC
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	defer rows.Close()
+
+	lowered := make([]string, len(terms))
+	for i, term := range terms {
+		lowered[i] = strings.ToLower(term)
+	}
+
+	var matches []models.MessageMatch
+	for rows.Next() && len(matches) < limit {
+		var match models.MessageMatch
+		var speaker string
+		err := rows.Scan(
+			&match.ID,
+			&match.ConversationID,
+			&speaker,
+			&match.Content,
+			&match.CreatedAt,
+			&match.ConversationTitle,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if match.Content, err = crypt.OpenString(match.Content); err != nil {
+			return nil, fmt.Errorf("failed to read message: %w", err)
+		}
+
+		content := strings.ToLower(match.Content)
+		found := true
+		for _, term := range lowered {
+			if !strings.Contains(content, term) {
+				found = false
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		match.Speaker = models.ParseSpeakerType(speaker)
+		match.Snippet = snippet(match.Content, terms[0])
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// snippet cuts content down to the text around the first match of term.
+func snippet(content, term string) string {
+	text := []rune(strings.Join(strings.Fields(content), " "))
+	lower := []rune(strings.ToLower(string(text)))
+	needle := []rune(strings.ToLower(term))
+
+	at := 0
+	for i := 0; i+len(needle) <= len(lower) && len(lower) == len(text); i++ {
+		if string(lower[i:i+len(needle)]) == string(needle) {
+			at = i
+			break
+		}
+	}
+
+	start := max(at-snippetRunes, 0)
+	end := min(at+len(needle)+snippetRunes, len(text))
+	out := string(text[start:end])
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(text) {
+		out += "…"
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MetaMessagesIndexed is set once every transcript has been copied into the
+// search index.
+const MetaMessagesIndexed = "messages_indexed"
+
+// GetMeta returns the value stored under key, or "" when it is unset
+func (db *DB) GetMeta(key string) (string, error) {
+	var value string
+	err := db.QueryRow(`SELECT value FROM meta WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get meta %s: %w", key, err)
+	}
+
+	return value, nil
+}
+
+// SetMeta stores value under key, replacing any earlier value
+func (db *DB) SetMeta(key, value string) error {
+	query := `
+		INSERT INTO meta (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value
+	`
+
+	if _, err := db.Exec(query, key, value); err != nil {
+		return fmt.Errorf("failed to set meta %s: %w", key, err)
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/nraghuveer/vibecast/lib/crypt"
 	"github.com/nraghuveer/vibecast/lib/models"
 )
 
@@ -16,6 +17,18 @@ type Template struct {
 	UpdatedAt time.Time
 }
 
+// open decrypts the columns stored sealed.
+func (t *Template) open() error {
+	var err error
+	if t.Topic, err = crypt.OpenString(t.Topic); err != nil {
+		return fmt.Errorf("failed to read template topic: %w", err)
+	}
+	if t.Persona, err = crypt.OpenString(t.Persona); err != nil {
+		return fmt.Errorf("failed to read template persona: %w", err)
+	}
+	return nil
+}
+
 func (db *DB) CreateTemplate(t models.Template) error {
 	query := `
 		INSERT INTO templates (id, name, topic, persona)
@@ -27,7 +40,7 @@ func (db *DB) CreateTemplate(t models.Template) error {
 			updated_at = CURRENT_TIMESTAMP
 	`
 
-	_, err := db.Exec(query, t.ID, t.Name, t.Topic, t.Persona)
+	_, err := db.Exec(query, t.ID, t.Name, crypt.SealString(t.Topic), crypt.SealString(t.Persona))
 	if err != nil {
 		return fmt.Errorf("failed to create template: %w", err)
 	}
@@ -55,6 +68,9 @@ func (db *DB) GetTemplate(id string) (*Template, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get template: %w", err)
 	}
+	if err := t.open(); err != nil {
+		return nil, err
+	}
 
 	return &t, nil
 }
@@ -86,6 +102,9 @@ func (db *DB) GetAllTemplates() ([]Template, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan template: %w", err)
 		}
+		if err := t.open(); err != nil {
+			return nil, err
+		}
 		templates = append(templates, t)
 	}
 
@@ -99,7 +118,7 @@ func (db *DB) UpdateTemplate(t models.Template) error {
 		WHERE id = ?
 	`
 
-	result, err := db.Exec(query, t.Name, t.Topic, t.Persona, t.ID)
+	result, err := db.Exec(query, t.Name, crypt.SealString(t.Topic), crypt.SealString(t.Persona), t.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update template: %w", err)
 	}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/models"
+)
+
+type Voice struct {
+	ID           string
+	Name         string
+	Provider     string
+	VoiceID      string
+	Speed        float64
+	Instructions string
+	Description  string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Profile converts the row into a models.VoiceProfile
+func (v Voice) Profile() models.VoiceProfile {
+	return models.VoiceProfile{
+		ID:           v.ID,
+		Name:         v.Name,
+		Provider:     v.Provider,
+		VoiceID:      v.VoiceID,
+		Speed:        v.Speed,
+		Instructions: v.Instructions,
+		Description:  v.Description,
+	}
+}
+
+func (db *DB) CreateVoice(v models.VoiceProfile) error {
+	query := `
+		INSERT INTO voices (id, name, provider, voice_id, speed, instructions, description)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			provider = excluded.provider,
+			voice_id = excluded.voice_id,
+			speed = excluded.speed,
+			instructions = excluded.instructions,
+			description = excluded.description
+	`
+
+	_, err := db.Exec(query, v.ID, v.Name, v.Provider, v.VoiceID, v.Speed, v.Instructions, v.Description)
+	if err != nil {
+		return fmt.Errorf("failed to create voice: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) GetVoice(id string) (*Voice, error) {
+	query := `
+		SELECT id, name, provider, voice_id, speed, instructions, description, created_at, updated_at
+		FROM voices
+		WHERE id = ?
+	`
+
+	var v Voice
+	err := db.QueryRow(query, id).Scan(
+		&v.ID,
+		&v.Name,
+		&v.Provider,
+		&v.VoiceID,
+		&v.Speed,
+		&v.Instructions,
+		&v.Description,
+		&v.CreatedAt,
+		&v.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("voice not found")
+		}
+		return nil, fmt.Errorf("failed to get voice: %w", err)
+	}
+
+	return &v, nil
+}
+
+func (db *DB) GetAllVoices() ([]Voice, error) {
+	query := `
+		SELECT id, name, provider, voice_id, speed, instructions, description, created_at, updated_at
+		FROM voices
+		ORDER BY provider, name
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voices: %w", err)
+	}
+	defer rows.Close()
+
+	var voices []Voice
+	for rows.Next() {
+		var v Voice
+		err := rows.Scan(
+			&v.ID,
+			&v.Name,
+			&v.Provider,
+			&v.VoiceID,
+			&v.Speed,
+			&v.Instructions,
+			&v.Description,
+			&v.CreatedAt,
+			&v.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan voice: %w", err)
+		}
+		voices = append(voices, v)
+	}
+
+	return voices, nil
+}
+
+func (db *DB) DeleteVoice(id string) error {
+	query := `DELETE FROM voices WHERE id = ?`
+
+	result, err := db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete voice: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("voice not found")
+	}
+
+	return nil
+}
+
+func (db *DB) VoiceExists(id string) (bool, error) {
+	query := `SELECT COUNT(*) FROM voices WHERE id = ?`
+
+	var count int
+	err := db.QueryRow(query, id).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check voice existence: %w", err)
+	}
+
+	return count > 0, nil
+}
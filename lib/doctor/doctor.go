@@ -0,0 +1,539 @@
+// Package doctor checks that the database, the conversation folders and the
+// config agree with each other, and repairs what it safely can.
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/audio"
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/storage"
+)
+
+// Severity says how much an issue matters.
+type Severity string
+
+const (
+	Error   Severity = "error"   // data is missing, unreachable or unreadable
+	Warning Severity = "warning" // works, but not as configured or indexed
+)
+
+// Repair kinds. Nothing is removed outright except unfinished deletions and
+// database rows whose data is already gone; everything else is moved to
+// <data_dir>/quarantine.
+const (
+	RepairReindex    = "reindex"
+	RepairCreate     = "create"
+	RepairConvert    = "convert"
+	RepairQuarantine = "quarantine"
+	RepairDelete     = "delete"
+)
+
+// quarantineDirName holds whatever repairs move out of the way, one folder
+// per run.
+const quarantineDirName = "quarantine"
+
+// Issue is one problem found by Check.
+type Issue struct {
+	Severity       Severity
+	ConversationID string
+	Path           string
+	Problem        string
+	Repair         string // "" when there is nothing safe to do
+	RepairDetail   string
+
+	fix func() error
+}
+
+// Report is the result of a check.
+type Report struct {
+	DataDir       string
+	DBPath        string
+	Conversations int
+	Issues        []Issue
+}
+
+// Count returns how many issues have the given severity.
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Repairable returns the issues that have a repair.
+func (r *Report) Repairable() []Issue {
+	var issues []Issue
+	for _, issue := range r.Issues {
+		if issue.fix != nil {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// Fix applies an issue's repair.
+func (i Issue) Fix() error {
+	if i.fix == nil {
+		return fmt.Errorf("no repair for this issue")
+	}
+	return i.fix()
+}
+
+type checker struct {
+	db            *db.DB
+	dataDir       string
+	quarantineDir string
+	report        *Report
+}
+
+// Check inspects the config, the database and every conversation folder.
+// It changes nothing; repairs run only through Issue.Fix.
+func Check(database *db.DB) (*Report, error) {
+	dataDir := config.GetDataDir()
+	c := &checker{
+		db:            database,
+		dataDir:       dataDir,
+		quarantineDir: filepath.Join(dataDir, quarantineDirName, time.Now().Format("20060102-150405")),
+		report:        &Report{DataDir: dataDir, DBPath: config.GetDBPath()},
+	}
+
+	c.checkConfig()
+	if err := c.checkConversations(); err != nil {
+		return c.report, err
+	}
+	return c.report, nil
+}
+
+func (c *checker) add(issue Issue) {
+	c.report.Issues = append(c.report.Issues, issue)
+}
+
+func (c *checker) checkConfig() {
+	cfg := config.Get()
+	if cfg == nil {
+		c.add(Issue{Severity: Error, Path: config.GetConfigPath(), Problem: "config is not loaded"})
+		return
+	}
+	configPath := config.GetConfigPath()
+
+	roles := []struct{ setting, provider string }{
+		{"ai.conversation_provider", config.GetConversationProvider()},
+		{"ai.speech_to_text", config.GetSpeechToTextProvider()},
+		{"ai.text_to_speech", config.GetTextToSpeechProvider()},
+	}
+	keyChecked := map[string]bool{}
+	for _, role := range roles {
+		provider, err := config.GetProviderConfig(role.provider)
+		if err != nil {
+			c.add(Issue{Severity: Error, Path: configPath, Problem: fmt.Sprintf("%s names provider %q, which has no providers entry", role.setting, role.provider)})
+			continue
+		}
+		if (role.setting == "ai.speech_to_text" && provider.STTBinary != "") || keyChecked[role.provider] {
+			continue
+		}
+		keyChecked[role.provider] = true
+		if _, err := config.GetProviderAPIKey(role.provider); err != nil {
+			c.add(Issue{Severity: Warning, Path: configPath, Problem: fmt.Sprintf("provider %q has no API key: %v", role.provider, err)})
+		}
+	}
+
+	for name, provider := range cfg.Providers {
+		if format := strings.ToLower(strings.TrimSpace(provider.TTSFormat)); format != "" && !slices.Contains(config.SupportedTTSFormats, format) {
+			c.add(Issue{Severity: Warning, Path: configPath, Problem: fmt.Sprintf("providers.%s.tts_format %q is not supported; wav is used instead", name, provider.TTSFormat)})
+		}
+		if binary := config.ExpandPath(provider.STTBinary); binary != "" {
+			if _, err := exec.LookPath(binary); err != nil {
+				c.add(Issue{Severity: Warning, Path: configPath, Problem: fmt.Sprintf("providers.%s.stt_binary %q not found", name, provider.STTBinary)})
+			}
+		}
+	}
+
+	if hostVoice := strings.TrimSpace(cfg.AI.HostVoice); hostVoice != "" {
+		if exists, err := c.db.VoiceExists(hostVoice); err == nil && !exists {
+			c.add(Issue{Severity: Warning, Path: configPath, Problem: fmt.Sprintf("ai.host_voice %q is not a known voice profile", hostVoice)})
+		}
+	}
+
+	episode := config.GetEpisodeConfig()
+	for setting, path := range map[string]string{"episode.intro": episode.Intro, "episode.outro": episode.Outro} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			c.add(Issue{Severity: Warning, Path: configPath, Problem: fmt.Sprintf("%s %q can't be read: %v", setting, path, err)})
+		}
+	}
+
+	switch strings.ToLower(config.GetPublishConfig().Format) {
+	case audio.FormatMP3, audio.FormatOpus, audio.FormatFLAC, audio.FormatWAV:
+	default:
+		c.add(Issue{Severity: Warning, Path: configPath, Problem: fmt.Sprintf("publish.format %q is not supported", config.GetPublishConfig().Format)})
+	}
+}
+
+func (c *checker) checkConversations() error {
+	conversations, err := c.db.GetAllConversations()
+	if err != nil {
+		return err
+	}
+	c.report.Conversations = len(conversations)
+
+	conversationsDir, err := storage.GetConversationsDir()
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(conversationsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read conversations directory: %w", err)
+	}
+	folders := map[string]bool{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			folders[entry.Name()] = true
+		}
+	}
+
+	rows := map[string]bool{}
+	for _, conv := range conversations {
+		rows[conv.ID] = true
+		if !folders[conv.ID] {
+			if err := c.checkMissingFolder(conv); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.checkTranscript(conv.ID); err != nil {
+			return err
+		}
+		if err := c.checkAudio(conv.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || rows[name] {
+			continue
+		}
+		dir := filepath.Join(conversationsDir, name)
+		if storage.IsTrashDir(name) {
+			c.add(Issue{
+				Severity:     Warning,
+				Path:         dir,
+				Problem:      "left over from a deletion that didn't finish",
+				Repair:       RepairDelete,
+				RepairDetail: "remove the folder",
+				fix: func() error {
+					if err := os.RemoveAll(dir); err != nil {
+						return fmt.Errorf("failed to remove folder: %w", err)
+					}
+					return nil
+				},
+			})
+			continue
+		}
+		c.add(Issue{
+			Severity:       Error,
+			ConversationID: name,
+			Path:           dir,
+			Problem:        "folder has no database row",
+			Repair:         RepairQuarantine,
+			RepairDetail:   "move the folder to " + c.quarantinePath(dir),
+			fix:            func() error { return c.quarantine(dir) },
+		})
+	}
+	return nil
+}
+
+// checkMissingFolder handles a row whose folder is gone. The search index
+// still holds the messages' text, so the transcript can be rebuilt from it.
+func (c *checker) checkMissingFolder(conv db.Conversation) error {
+	messages, err := c.db.GetMessages(conv.ID)
+	if err != nil {
+		return err
+	}
+
+	dir, err := storage.GetConversationDir(conv.ID)
+	if err != nil {
+		return err
+	}
+	issue := Issue{
+		Severity:       Error,
+		ConversationID: conv.ID,
+		Path:           dir,
+		Problem:        fmt.Sprintf("database row %q has no folder", conv.Title),
+	}
+	if len(messages) > 0 {
+		issue.Repair = RepairCreate
+		issue.RepairDetail = fmt.Sprintf("recreate the folder and rebuild the transcript from the search index (%d messages; audio, answer timings and model details are lost)", len(messages))
+		issue.fix = func() error {
+			if err := c.rebuildTranscript(conv.ID); err != nil {
+				return err
+			}
+			return c.forgetAudio(conv.ID)
+		}
+	} else {
+		issue.Repair = RepairDelete
+		issue.RepairDetail = "delete the database row; nothing of the conversation remains"
+		issue.fix = func() error { return c.db.DeleteConversation(conv.ID) }
+	}
+	c.add(issue)
+	return nil
+}
+
+func (c *checker) checkTranscript(conversationID string) error {
+	check, err := storage.CheckTranscript(conversationID)
+	if err != nil {
+		return err
+	}
+	transcriptPath, err := storage.TranscriptPath(conversationID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !check.Exists:
+		indexed, err := c.db.CountMessages(conversationID)
+		if err != nil {
+			return err
+		}
+		issue := Issue{Severity: Error, ConversationID: conversationID, Path: transcriptPath, Problem: "transcript is missing", Repair: RepairCreate}
+		if indexed > 0 {
+			issue.RepairDetail = fmt.Sprintf("rebuild the transcript from the search index (%d messages; answer timings, model details and clip names are lost)", indexed)
+			issue.fix = func() error { return c.rebuildTranscript(conversationID) }
+		} else {
+			issue.RepairDetail = "create an empty transcript"
+			issue.fix = func() error { return storage.CreateTranscript(conversationID) }
+		}
+		c.add(issue)
+		return nil
+
+	case check.Legacy:
+		legacyPath := filepath.Join(filepath.Dir(transcriptPath), "transcript.txt")
+		c.add(Issue{
+			Severity:       Warning,
+			ConversationID: conversationID,
+			Path:           legacyPath,
+			Problem:        "legacy transcript.txt hasn't been converted to transcript.jsonl",
+			Repair:         RepairConvert,
+			RepairDetail:   "convert it to transcript.jsonl, keeping the original as transcript.txt.migrated",
+			fix:            func() error { return storage.MigrateTranscript(conversationID) },
+		})
+		return nil
+
+	case check.Unreadable > 0:
+		c.add(Issue{
+			Severity:       Error,
+			ConversationID: conversationID,
+			Path:           transcriptPath,
+			Problem:        fmt.Sprintf("%d transcript lines can't be read", check.Unreadable),
+			Repair:         RepairQuarantine,
+			RepairDetail:   fmt.Sprintf("copy the transcript to %s and keep only the %d readable messages", c.quarantinePath(transcriptPath), check.Records),
+			fix: func() error {
+				if err := c.quarantineCopy(transcriptPath); err != nil {
+					return err
+				}
+				messages, err := storage.LoadMessages(conversationID)
+				if err != nil {
+					return err
+				}
+				return storage.WriteTranscript(conversationID, messages)
+			},
+		})
+	}
+
+	indexed, err := c.db.CountMessages(conversationID)
+	if err != nil {
+		return err
+	}
+	if indexed < check.Records {
+		c.add(Issue{
+			Severity:       Warning,
+			ConversationID: conversationID,
+			Path:           transcriptPath,
+			Problem:        fmt.Sprintf("search index is missing %d of %d messages", check.Records-indexed, check.Records),
+			Repair:         RepairReindex,
+			RepairDetail:   "index the transcript's messages",
+			fix: func() error {
+				_, err := storage.IndexMessages(c.db, conversationID)
+				return err
+			},
+		})
+	}
+	return nil
+}
+
+func (c *checker) checkAudio(conversationID string) error {
+	files, err := c.db.GetAudioFiles(conversationID)
+	if err != nil {
+		return err
+	}
+	names, err := storage.ListAudioFiles(conversationID)
+	if err != nil {
+		return err
+	}
+	audioDir, err := storage.GetAudioDir(conversationID)
+	if err != nil {
+		return err
+	}
+
+	onDisk := map[string]bool{}
+	for _, name := range names {
+		onDisk[name] = true
+	}
+	indexed := map[string]bool{}
+	for _, f := range files {
+		indexed[f.Filename] = true
+		if onDisk[f.Filename] {
+			continue
+		}
+		filename := f.Filename
+		c.add(Issue{
+			Severity:       Error,
+			ConversationID: conversationID,
+			Path:           filepath.Join(audioDir, filename),
+			Problem:        "indexed clip is missing",
+			Repair:         RepairDelete,
+			RepairDetail:   "remove the clip from the index",
+			fix:            func() error { return c.db.DeleteAudioFileRecord(conversationID, filename) },
+		})
+	}
+
+	var unindexed []string
+	for _, name := range names {
+		if !storage.IsClipName(name) {
+			continue
+		}
+		clipPath := filepath.Join(audioDir, name)
+		data, err := os.ReadFile(clipPath)
+		if err != nil {
+			return fmt.Errorf("failed to read audio file: %w", err)
+		}
+		if data, err = crypt.Open(data); err == nil {
+			err = audio.Check(data)
+		}
+		if err != nil {
+			filename := name
+			c.add(Issue{
+				Severity:       Error,
+				ConversationID: conversationID,
+				Path:           clipPath,
+				Problem:        fmt.Sprintf("clip is unplayable: %v", err),
+				Repair:         RepairQuarantine,
+				RepairDetail:   "move the clip to " + c.quarantinePath(clipPath) + " and remove it from the index",
+				fix: func() error {
+					if err := c.quarantine(clipPath); err != nil {
+						return err
+					}
+					return c.db.DeleteAudioFileRecord(conversationID, filename)
+				},
+			})
+			continue
+		}
+		if !indexed[name] {
+			unindexed = append(unindexed, name)
+		}
+	}
+
+	if len(unindexed) > 0 {
+		c.add(Issue{
+			Severity:       Warning,
+			ConversationID: conversationID,
+			Path:           audioDir,
+			Problem:        fmt.Sprintf("%d clips are not indexed: %s", len(unindexed), strings.Join(unindexed, ", ")),
+			Repair:         RepairReindex,
+			RepairDetail:   "index them as guest speech",
+			fix: func() error {
+				_, err := storage.IndexAudioFiles(c.db, conversationID, unindexed)
+				return err
+			},
+		})
+	}
+	return nil
+}
+
+// forgetAudio removes the index records of clips that went with a lost folder.
+func (c *checker) forgetAudio(conversationID string) error {
+	files, err := c.db.GetAudioFiles(conversationID)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := c.db.DeleteAudioFileRecord(conversationID, f.Filename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildTranscript writes a transcript from the search index. The index
+// keeps each message's text and take, but not its timings, model or clips.
+func (c *checker) rebuildTranscript(conversationID string) error {
+	indexed, err := c.db.GetMessages(conversationID)
+	if err != nil {
+		return err
+	}
+	if _, err := storage.CreateConversationDir(conversationID); err != nil {
+		return err
+	}
+
+	messages := make([]storage.Message, len(indexed))
+	for i, m := range indexed {
+		messages[i] = storage.Message{
+			ID:        m.ID,
+			Timestamp: m.CreatedAt,
+			Speaker:   m.Speaker,
+			Content:   m.Content,
+			TakeOf:    m.TakeOf,
+			Alternate: m.Alternate,
+		}
+	}
+	return storage.WriteTranscript(conversationID, messages)
+}
+
+// quarantinePath is where a file under the data directory is moved to.
+func (c *checker) quarantinePath(path string) string {
+	rel, err := filepath.Rel(c.dataDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(path)
+	}
+	return filepath.Join(c.quarantineDir, rel)
+}
+
+func (c *checker) quarantine(path string) error {
+	dst := c.quarantinePath(path)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	if err := os.Rename(path, dst); err != nil {
+		return fmt.Errorf("failed to quarantine %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func (c *checker) quarantineCopy(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	dst := c.quarantinePath(path)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return fmt.Errorf("failed to quarantine %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
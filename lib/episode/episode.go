@@ -0,0 +1,497 @@
+package episode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/audio"
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/models"
+	"github.com/nraghuveer/vibecast/lib/storage"
+)
+
+// FileName is the base name of a rendered episode inside its conversation folder.
+const FileName = "episode"
+
+// Loudness is measured over windows of this length; windows quieter than
+// silenceDBFS are left out so pauses don't drag the average down.
+const (
+	loudnessWindow = 50 * time.Millisecond
+	silenceDBFS    = -50
+)
+
+// Clip is one piece of speech in the episode.
+type Clip struct {
+	Path      string
+	Speaker   models.SpeakerType
+	MessageID string // transcript message the clip voices; "" if unknown
+}
+
+// Options controls how clips are stitched together.
+type Options struct {
+	Gap          time.Duration // between clips of the same speaker
+	SpeakerGap   time.Duration // when the speaker changes
+	LoudnessDBFS float64       // every clip is normalized to this RMS level
+	PeakDBFS     float64       // ceiling no clip may peak above
+	SampleRate   int           // 0 uses the first clip's rate
+	Intro        string
+	Outro        string
+	BedVolumeDB  float64       // bed level relative to LoudnessDBFS
+	BedOverlap   time.Duration // how long beds fade under the speech
+	Formats      []string      // extra encodings made with ffmpeg
+}
+
+// OptionsFromConfig returns the options set under episode: in the config file.
+func OptionsFromConfig() Options {
+	cfg := config.GetEpisodeConfig()
+	return Options{
+		Gap:          time.Duration(cfg.GapMs) * time.Millisecond,
+		SpeakerGap:   time.Duration(cfg.SpeakerGapMs) * time.Millisecond,
+		LoudnessDBFS: cfg.LoudnessDBFS,
+		PeakDBFS:     cfg.PeakDBFS,
+		SampleRate:   cfg.SampleRate,
+		Intro:        cfg.Intro,
+		Outro:        cfg.Outro,
+		BedVolumeDB:  cfg.BedVolumeDB,
+		BedOverlap:   time.Duration(cfg.BedOverlapMs) * time.Millisecond,
+		Formats:      cfg.Formats,
+	}
+}
+
+// Result describes a rendered episode.
+type Result struct {
+	Files    []string // the WAV first, then any extra encodings
+	Duration time.Duration
+	Clips    int
+	Timeline []Placement // where each clip landed, in clip order
+	Skipped  []string    // extra encodings that weren't produced, with the reason
+}
+
+// Placement is the position of one clip in the rendered episode.
+type Placement struct {
+	Clip     Clip
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// RenderConversation renders every indexed clip of a conversation, in
+// transcript order and leaving out alternate takes, to episode.wav in the
+// conversation's folder.
+func RenderConversation(ctx context.Context, database *db.DB, conversationID string, opts Options) (*Result, error) {
+	clips, err := ConversationClips(database, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	conversationDir, err := storage.GetConversationDir(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return Render(ctx, clips, opts, filepath.Join(conversationDir, FileName+".wav"))
+}
+
+// ConversationClips returns the clips RenderConversation stitches together,
+// each with the message it voices. Audio predating the index is indexed
+// first. Conversations retention pruned clips from are refused rather than
+// rendered with gaps.
+func ConversationClips(database *db.DB, conversationID string) ([]Clip, error) {
+	conv, err := database.GetConversation(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conv.AudioPrunedAt.Valid {
+		return nil, fmt.Errorf("conversation %s had clips pruned on %s and can't be rendered in full", conversationID, conv.AudioPrunedAt.Time.Format("2006-01-02"))
+	}
+
+	files, err := database.GetAudioFiles(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		if _, err := storage.IndexAudioDir(database, conversationID); err != nil {
+			return nil, err
+		}
+		if files, err = database.GetAudioFiles(conversationID); err != nil {
+			return nil, err
+		}
+	}
+	messages, err := storage.LoadMessages(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	files = storage.CanonicalAudio(messages, files)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("conversation %s has no audio to render", conversationID)
+	}
+
+	audioDir, err := storage.GetAudioDir(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	links := storage.InferAudioLinks(messages, files)
+	clips := make([]Clip, 0, len(files))
+	for _, f := range files {
+		messageID := f.MessageID
+		if messageID == "" {
+			messageID = links[f.Filename]
+		}
+		clips = append(clips, Clip{Path: filepath.Join(audioDir, f.Filename), Speaker: f.Speaker, MessageID: messageID})
+	}
+	return clips, nil
+}
+
+// Layout places clips as Render would, without rendering: durations come
+// from the clips' headers, and only clips whose header doesn't give one are
+// decoded.
+func Layout(ctx context.Context, clips []Clip, opts Options) ([]Placement, error) {
+	var cursor time.Duration
+	if opts.Intro != "" {
+		bed, err := clipDuration(ctx, opts.Intro)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load music bed: %w", err)
+		}
+		cursor = max(bed-opts.BedOverlap, 0)
+	}
+
+	timeline := make([]Placement, 0, len(clips))
+	for i, clip := range clips {
+		duration, err := clipDuration(ctx, clip.Path)
+		if err != nil {
+			return nil, err
+		}
+		cursor += gapBefore(clips, i, opts)
+		timeline = append(timeline, Placement{Clip: clip, Start: cursor, Duration: duration})
+		cursor += duration
+	}
+	return timeline, nil
+}
+
+// clipDuration reads a clip's length from its header, decoding it when the
+// header doesn't say.
+func clipDuration(ctx context.Context, path string) (time.Duration, error) {
+	data, err := crypt.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read audio file: %w", err)
+	}
+	if info, err := audio.Probe(data); err == nil && info.Duration > 0 {
+		return info.Duration, nil
+	}
+
+	pcm, err := audio.DecodeFile(ctx, path, 0)
+	if err != nil {
+		return 0, err
+	}
+	return pcm.Duration(), nil
+}
+
+// gapBefore is the silence Render leaves before clips[i].
+func gapBefore(clips []Clip, i int, opts Options) time.Duration {
+	switch {
+	case i == 0:
+		return 0
+	case clips[i].Speaker == clips[i-1].Speaker:
+		return opts.Gap
+	default:
+		return opts.SpeakerGap
+	}
+}
+
+// Render stitches clips into a WAV at wavPath, then encodes any extra formats
+// with ffmpeg. The WAV is written to a temp file and renamed into place. Like
+// the clips it is made from, every file is sealed when encryption is on.
+func Render(ctx context.Context, clips []Clip, opts Options, wavPath string) (*Result, error) {
+	if len(clips) == 0 {
+		return nil, errors.New("no clips to render")
+	}
+
+	rate := opts.SampleRate
+	first, err := audio.DecodeFile(ctx, clips[0].Path, rate)
+	if err != nil {
+		return nil, err
+	}
+	if rate == 0 {
+		rate = first.SampleRate
+	}
+
+	tmpPath := wavPath + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create episode file: %w", err)
+	}
+	defer os.Remove(tmpPath)
+
+	writer, err := audio.NewWAVWriter(out, rate)
+	if err != nil {
+		out.Close()
+		return nil, fmt.Errorf("failed to write episode header: %w", err)
+	}
+
+	m := &mixer{w: writer}
+	overlap := samplesFor(opts.BedOverlap, rate)
+	var cursor int64
+	timeline := make([]Placement, 0, len(clips))
+
+	if opts.Intro != "" {
+		bed, err := loadBed(ctx, opts.Intro, rate, opts)
+		if err != nil {
+			out.Close()
+			return nil, err
+		}
+		fade(bed, overlap, false)
+		m.mix(0, bed)
+		cursor = max(int64(len(bed))-overlap, 0)
+	}
+
+	for i, clip := range clips {
+		pcm := first
+		if i > 0 {
+			if pcm, err = audio.DecodeFile(ctx, clip.Path, rate); err != nil {
+				out.Close()
+				return nil, err
+			}
+			cursor += samplesFor(gapBefore(clips, i, opts), rate)
+		}
+		normalize(pcm.Samples, rate, opts.LoudnessDBFS, opts.PeakDBFS)
+		timeline = append(timeline, Placement{
+			Clip:     clip,
+			Start:    durationOf(cursor, rate),
+			Duration: durationOf(int64(len(pcm.Samples)), rate),
+		})
+		m.mix(cursor, pcm.Samples)
+		cursor += int64(len(pcm.Samples))
+
+		// Keep the tail open so the outro can fade in underneath it.
+		if err := m.flush(cursor - overlap); err != nil {
+			out.Close()
+			return nil, fmt.Errorf("failed to write episode audio: %w", err)
+		}
+	}
+
+	if opts.Outro != "" {
+		bed, err := loadBed(ctx, opts.Outro, rate, opts)
+		if err != nil {
+			out.Close()
+			return nil, err
+		}
+		fade(bed, overlap, true)
+		m.mix(max(cursor-overlap, 0), bed)
+	}
+
+	if err := m.flush(m.end()); err != nil {
+		out.Close()
+		return nil, fmt.Errorf("failed to write episode audio: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		out.Close()
+		return nil, fmt.Errorf("failed to finalize episode file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize episode file: %w", err)
+	}
+	if crypt.Enabled() {
+		if _, err := crypt.ResealFile(tmpPath, true); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.Rename(tmpPath, wavPath); err != nil {
+		return nil, fmt.Errorf("failed to save episode file: %w", err)
+	}
+
+	result := &Result{
+		Files:    []string{wavPath},
+		Duration: durationOf(m.written, rate),
+		Clips:    len(clips),
+		Timeline: timeline,
+	}
+
+	for _, format := range opts.Formats {
+		path, err := Encode(ctx, wavPath, format)
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", format, err))
+			continue
+		}
+		result.Files = append(result.Files, path)
+	}
+
+	return result, nil
+}
+
+// encoderArgs are the ffmpeg codec settings for each extra format.
+var encoderArgs = map[string][]string{
+	audio.FormatMP3:  {"-codec:a", "libmp3lame", "-b:a", "128k"},
+	audio.FormatOpus: {"-codec:a", "libopus", "-b:a", "64k"},
+	audio.FormatFLAC: {"-codec:a", "flac"},
+}
+
+// Encode converts a rendered WAV to format with ffmpeg, next to the WAV. A
+// sealed WAV is decrypted to a temp file for ffmpeg, and the output sealed.
+func Encode(ctx context.Context, wavPath, format string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	args, ok := encoderArgs[format]
+	if !ok {
+		return "", fmt.Errorf("unsupported episode format %q", format)
+	}
+	ffmpeg := audio.FFmpegPath()
+	if ffmpeg == "" {
+		return "", audio.ErrFFmpegMissing
+	}
+
+	source, cleanup, err := crypt.PlainFile(wavPath)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	outPath := strings.TrimSuffix(wavPath, filepath.Ext(wavPath)) + "." + format
+	cmdArgs := append([]string{"-y", "-v", "error", "-i", source}, args...)
+	cmdArgs = append(cmdArgs, outPath)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpeg, cmdArgs...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("ffmpeg failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	if crypt.Enabled() {
+		if _, err := crypt.ResealFile(outPath, true); err != nil {
+			return "", err
+		}
+	}
+	return outPath, nil
+}
+
+// loadBed decodes an intro/outro bed and sets it BedVolumeDB below the speech.
+func loadBed(ctx context.Context, path string, rate int, opts Options) ([]float32, error) {
+	pcm, err := audio.DecodeFile(ctx, path, rate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load music bed: %w", err)
+	}
+	normalize(pcm.Samples, rate, opts.LoudnessDBFS+opts.BedVolumeDB, opts.PeakDBFS)
+	return pcm.Samples, nil
+}
+
+// normalize scales samples in place so their gated RMS is targetDBFS, lowering
+// the gain if needed so no sample peaks above peakDBFS.
+func normalize(samples []float32, rate int, targetDBFS, peakDBFS float64) {
+	loudness, peak := measure(samples, rate)
+	if loudness == 0 || peak == 0 {
+		return
+	}
+	gain := dbToGain(targetDBFS) / loudness
+	if ceiling := dbToGain(peakDBFS); peak*gain > ceiling {
+		gain = ceiling / peak
+	}
+	for i := range samples {
+		samples[i] = float32(float64(samples[i]) * gain)
+	}
+}
+
+// measure returns the RMS over non-silent windows and the absolute peak.
+func measure(samples []float32, rate int) (rms, peak float64) {
+	size := max(int(samplesFor(loudnessWindow, rate)), 1)
+	gate := dbToGain(silenceDBFS)
+
+	var energy float64
+	var counted int
+	for start := 0; start < len(samples); start += size {
+		end := min(start+size, len(samples))
+		var sum float64
+		for _, s := range samples[start:end] {
+			v := float64(s)
+			sum += v * v
+			peak = math.Max(peak, math.Abs(v))
+		}
+		if math.Sqrt(sum/float64(end-start)) < gate {
+			continue
+		}
+		energy += sum
+		counted += end - start
+	}
+	if counted == 0 {
+		return 0, peak
+	}
+	return math.Sqrt(energy / float64(counted)), peak
+}
+
+// fade ramps the last n samples down to silence, or the first n up from it.
+func fade(samples []float32, n int64, in bool) {
+	n = min(n, int64(len(samples)))
+	for i := int64(0); i < n; i++ {
+		g := float32(i) / float32(n)
+		if in {
+			samples[i] *= g
+		} else {
+			samples[int64(len(samples))-1-i] *= g
+		}
+	}
+}
+
+func dbToGain(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+func samplesFor(d time.Duration, rate int) int64 {
+	return int64(d) * int64(rate) / int64(time.Second)
+}
+
+func durationOf(samples int64, rate int) time.Duration {
+	return time.Duration(samples * int64(time.Second) / int64(rate))
+}
+
+// mixer sums overlapping audio and streams finished samples to a WAVWriter,
+// so only the region still being mixed is held in memory.
+type mixer struct {
+	w       *audio.WAVWriter
+	written int64     // samples already flushed
+	buf     []float32 // samples from written onwards
+}
+
+// mix adds samples starting at absolute position at, which must not be
+// before what has already been flushed.
+func (m *mixer) mix(at int64, samples []float32) {
+	offset := at - m.written
+	if offset < 0 {
+		samples = samples[min(-offset, int64(len(samples))):]
+		offset = 0
+	}
+	if need := offset + int64(len(samples)); need > int64(len(m.buf)) {
+		m.buf = append(m.buf, make([]float32, need-int64(len(m.buf)))...)
+	}
+	for i, s := range samples {
+		m.buf[offset+int64(i)] += s
+	}
+}
+
+// end is the absolute position just past the last mixed sample.
+func (m *mixer) end() int64 {
+	return m.written + int64(len(m.buf))
+}
+
+// flush writes everything before absolute position upTo, padding with
+// silence when upTo is past the mixed audio.
+func (m *mixer) flush(upTo int64) error {
+	n := upTo - m.written
+	if n <= 0 {
+		return nil
+	}
+	if n > int64(len(m.buf)) {
+		m.buf = append(m.buf, make([]float32, n-int64(len(m.buf)))...)
+	}
+	if err := m.w.Write(m.buf[:n]); err != nil {
+		return err
+	}
+	m.buf = append(m.buf[:0], m.buf[n:]...)
+	m.written = upTo
+	return nil
+}
@@ -0,0 +1,300 @@
+// Package library changes whole conversations, keeping the database row and
+// the conversation folder in step.
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/episode"
+	"github.com/nraghuveer/vibecast/lib/models"
+	"github.com/nraghuveer/vibecast/lib/storage"
+	"github.com/nraghuveer/vibecast/lib/ttscache"
+)
+
+// Delete removes a conversation's row, with its clips and messages, and its
+// folder. The folder is moved aside first and put back if the row can't be
+// deleted, so a failure leaves the conversation as it was.
+func Delete(database *db.DB, id string) error {
+	pending, err := storage.StageConversationDirDelete(id)
+	if err != nil {
+		return err
+	}
+
+	if err := database.DeleteConversation(id); err != nil {
+		if restoreErr := pending.Restore(); restoreErr != nil {
+			return fmt.Errorf("%w; %v", err, restoreErr)
+		}
+		return err
+	}
+
+	if err := pending.Commit(); err != nil {
+		return fmt.Errorf("conversation deleted but its files remain: %w", err)
+	}
+	return nil
+}
+
+// Duplicate starts a new, empty conversation with the same title, topic,
+// persona, voices and provider as id.
+func Duplicate(database *db.DB, id string) (*models.Conversation, error) {
+	c, err := database.GetConversation(id)
+	if err != nil {
+		return nil, err
+	}
+
+	conv := models.Conversation{
+		ID:                 uuid.New().String(),
+		Title:              c.Title + " (copy)",
+		Topic:              c.Topic,
+		Persona:            c.Persona,
+		VoiceID:            c.VoiceID,
+		VoiceName:          c.VoiceName,
+		VoiceProfileID:     c.VoiceProfileID,
+		HostVoiceProfileID: c.HostVoiceProfileID,
+		Provider:           c.Provider,
+		CreatedAt:          time.Now(),
+	}
+
+	if _, err := storage.CreateConversationDir(conv.ID); err != nil {
+		return nil, err
+	}
+	if err := storage.CreateTranscript(conv.ID); err != nil {
+		storage.DeleteConversationDir(conv.ID)
+		return nil, err
+	}
+	if err := database.CreateConversation(conv); err != nil {
+		storage.DeleteConversationDir(conv.ID)
+		return nil, err
+	}
+
+	return &conv, nil
+}
+
+// Fork starts a new conversation from id's history up to and including
+// messageID: the same settings, the transcript to that point and the clips
+// voicing it. Copied messages get new ids; the fork records id and
+// messageID as its parent.
+func Fork(database *db.DB, id, messageID string) (*models.Conversation, error) {
+	c, err := database.GetConversation(id)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := storage.LoadMessages(id)
+	if err != nil {
+		return nil, err
+	}
+	files, err := database.GetAudioFiles(id)
+	if err != nil {
+		return nil, err
+	}
+	links := storage.InferAudioLinks(messages, files)
+
+	// Only the takes that were played carry over.
+	files = storage.CanonicalAudio(messages, files)
+	messages = storage.Canonical(messages)
+	end := -1
+	for i, msg := range messages {
+		if msg.ID == messageID {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, fmt.Errorf("message not found")
+	}
+
+	messageIDs := map[string]string{}
+	for _, msg := range messages[:end+1] {
+		messageIDs[msg.ID] = uuid.New().String()
+	}
+
+	conv := models.Conversation{
+		ID:                  uuid.New().String(),
+		Title:               c.Title + " (fork)",
+		Topic:               c.Topic,
+		Persona:             c.Persona,
+		VoiceID:             c.VoiceID,
+		VoiceName:           c.VoiceName,
+		VoiceProfileID:      c.VoiceProfileID,
+		HostVoiceProfileID:  c.HostVoiceProfileID,
+		Provider:            c.Provider,
+		CreatedAt:           time.Now(),
+		ParentID:            c.ID,
+		ForkedFromMessageID: messageID,
+	}
+
+	if _, err := storage.CreateConversationDir(conv.ID); err != nil {
+		return nil, err
+	}
+	if err := storage.CreateTranscript(conv.ID); err != nil {
+		storage.DeleteConversationDir(conv.ID)
+		return nil, err
+	}
+	if err := database.CreateConversation(conv); err != nil {
+		storage.DeleteConversationDir(conv.ID)
+		return nil, err
+	}
+	rollback := func(err error) (*models.Conversation, error) {
+		database.DeleteConversation(conv.ID)
+		storage.DeleteConversationDir(conv.ID)
+		return nil, err
+	}
+	// The parent may be missing clips the fork would have copied.
+	if c.AudioPrunedAt.Valid {
+		if err := database.MarkAudioPruned(conv.ID, c.AudioPrunedAt.Time); err != nil {
+			return rollback(err)
+		}
+	}
+
+	for _, f := range files {
+		linked := f.MessageID
+		if linked == "" {
+			linked = links[f.Filename]
+		}
+		newID, ok := messageIDs[linked]
+		if !ok {
+			continue
+		}
+		if err := storage.CopyAudioFile(id, conv.ID, f.Filename); err != nil {
+			// A clip already gone from disk isn't worth failing the fork.
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return rollback(err)
+		}
+		f.ConversationID = conv.ID
+		f.MessageID = newID
+		if err := database.RecordAudioFile(f); err != nil {
+			return rollback(err)
+		}
+	}
+
+	for _, msg := range messages[:end+1] {
+		msg.ID = messageIDs[msg.ID]
+		msg.TakeOf = ""
+		if _, err := storage.AppendRecord(database, conv.ID, msg); err != nil {
+			return rollback(err)
+		}
+	}
+
+	return &conv, nil
+}
+
+// Rewind removes messageID and everything recorded after it from a
+// conversation: the transcript records, their index rows and the clips
+// voicing them. It returns the removed records.
+func Rewind(database *db.DB, conversationID, messageID string) ([]storage.Message, error) {
+	messages, err := storage.LoadMessages(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := database.GetAudioFiles(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	links := storage.InferAudioLinks(messages, files)
+
+	removed, err := storage.TruncateTranscript(database, conversationID, messageID)
+	if err != nil {
+		return removed, err
+	}
+
+	removedIDs := map[string]bool{}
+	for _, msg := range removed {
+		removedIDs[msg.ID] = true
+	}
+	for _, f := range files {
+		linked := f.MessageID
+		if linked == "" {
+			linked = links[f.Filename]
+		}
+		if !removedIDs[linked] {
+			continue
+		}
+		if err := storage.DeleteAudioFile(conversationID, f.Filename); err != nil {
+			return removed, err
+		}
+		if err := database.DeleteAudioFileRecord(conversationID, f.Filename); err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// ResealResult counts what Reseal rewrote.
+type ResealResult struct {
+	Transcripts int
+	Clips       int
+	Cached      int // TTS cache files
+	Values      int // database values
+	Rendered    int // rendered episodes and captions
+}
+
+// Reseal rewrites every conversation's transcript, clips, rendered episodes
+// and captions, the TTS cache
+// and the sensitive database columns: encrypted with the loaded key when
+// seal is set, otherwise in the clear. Anything already in the wanted form
+// is skipped, so an interrupted run can simply be repeated.
+func Reseal(database *db.DB, seal bool) (ResealResult, error) {
+	var result ResealResult
+
+	conversationsDir, err := storage.GetConversationsDir()
+	if err != nil {
+		return result, err
+	}
+	entries, err := os.ReadDir(conversationsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return result, fmt.Errorf("failed to read conversations directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || storage.IsTrashDir(entry.Name()) {
+			continue
+		}
+		id := entry.Name()
+
+		changed, err := storage.ResealTranscript(id, seal)
+		if err != nil {
+			return result, fmt.Errorf("conversation %s: %w", id, err)
+		}
+		if changed {
+			result.Transcripts++
+		}
+		clips, err := storage.ResealAudio(id, seal)
+		result.Clips += clips
+		if err != nil {
+			return result, fmt.Errorf("conversation %s: %w", id, err)
+		}
+
+		rendered, _ := filepath.Glob(filepath.Join(conversationsDir, id, episode.FileName+".*"))
+		for _, path := range rendered {
+			if strings.HasSuffix(path, ".tmp") {
+				continue
+			}
+			changed, err := crypt.ResealFile(path, seal)
+			if err != nil {
+				return result, fmt.Errorf("conversation %s: %w", id, err)
+			}
+			if changed {
+				result.Rendered++
+			}
+		}
+	}
+
+	if result.Cached, err = ttscache.Reseal(seal); err != nil {
+		return result, err
+	}
+	if result.Values, err = database.ResealColumns(seal); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/nraghuveer/vibecast/lib/models"
 )
 
 type Client struct {
@@ -71,13 +73,19 @@ func (c *Client) prepareTextForSpeech(ctx context.Context, provider, persona, to
 	return out, nil
 }
 
+// SynthesizeHostSpeech voices one of the host's lines. The host's own words
+// are spoken as typed, without the guest's speech preparation.
+func (c *Client) SynthesizeHostSpeech(ctx context.Context, ttsProvider string, voice models.VoiceProfile, text string) ([]byte, error) {
+	return c.openAITTS(ctx, ttsProvider, voice, text)
+}
+
 // SynthesizeGuestSpeech converts guest text into audio.
 // It first normalizes text into natural, human-like speech, then calls the TTS endpoint.
 // Returns the synthesized audio bytes and the speakable text actually sent to TTS.
-func (c *Client) SynthesizeGuestSpeech(ctx context.Context, prepProvider, ttsProvider, persona, topic, voice, text string) ([]byte, string, error) {
+func (c *Client) SynthesizeGuestSpeech(ctx context.Context, prepProvider, ttsProvider, persona, topic string, voice models.VoiceProfile, text string) ([]byte, string, error) {
 	speakable := text
 	if strings.TrimSpace(prepProvider) != "" {
-		if prepared, err := c.prepareTextForSpeech(ctx, prepProvider, persona, topic, voice, text); err == nil && strings.TrimSpace(prepared) != "" {
+		if prepared, err := c.prepareTextForSpeech(ctx, prepProvider, persona, topic, voice.Name, text); err == nil && strings.TrimSpace(prepared) != "" {
 			speakable = prepared
 		}
 	}
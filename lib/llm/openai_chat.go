@@ -199,7 +199,7 @@ func (c *Client) streamChatCompletion(ctx context.Context, provider string, mess
 					ch <- StreamEvent{Delta: choice.Delta.Content}
 				}
 				if choice.FinishReason != nil {
-					ch <- StreamEvent{Done: true}
+					ch <- StreamEvent{Done: true, FinishReason: *choice.FinishReason}
 					return
 				}
 			}
@@ -0,0 +1,122 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/config"
+)
+
+type sttVerboseResponse struct {
+	Text     string `json:"text"`
+	Language string `json:"language"`
+	Segments []struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	} `json:"segments"`
+}
+
+// openAITranscriber calls an OpenAI-compatible /audio/transcriptions endpoint.
+type openAITranscriber struct {
+	client   *Client
+	provider string
+}
+
+func (t *openAITranscriber) Transcribe(ctx context.Context, wavPath string) (*Transcription, error) {
+	apiKey, err := config.GetProviderAPIKey(t.provider)
+	if err != nil {
+		return nil, err
+	}
+	url, err := config.GetProviderSTTURL(t.provider)
+	if err != nil {
+		return nil, err
+	}
+	model, err := config.GetProviderSTTModel(t.provider)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(model) == "" {
+		return nil, fmt.Errorf("stt model not configured for provider %s", t.provider)
+	}
+	cfg, err := config.GetProviderConfig(t.provider)
+	if err != nil {
+		return nil, err
+	}
+
+	audio, err := os.ReadFile(wavPath)
+	if err != nil {
+		return nil, fmt.Errorf("read stt input: %w", err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filepath.Base(wavPath))
+	if err != nil {
+		return nil, fmt.Errorf("build stt request: %w", err)
+	}
+	if _, err := part.Write(audio); err != nil {
+		return nil, fmt.Errorf("build stt request: %w", err)
+	}
+	_ = form.WriteField("model", model)
+	_ = form.WriteField("response_format", "verbose_json")
+	if lang := strings.TrimSpace(cfg.STTLanguage); lang != "" {
+		_ = form.WriteField("language", lang)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("build stt request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	resp, err := t.client.httpClient.Do(req)
+	if err != nil {
+		log.Printf("stt request failed: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		log.Printf("stt error: status=%s body=%s", resp.Status, strings.TrimSpace(string(b)))
+		return nil, fmt.Errorf("stt failed: %s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+
+	var out sttVerboseResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		log.Printf("stt decode error: %v", err)
+		return nil, fmt.Errorf("decode stt response: %w", err)
+	}
+
+	result := &Transcription{
+		Text:     strings.TrimSpace(out.Text),
+		Language: out.Language,
+	}
+	for _, seg := range out.Segments {
+		result.Segments = append(result.Segments, TranscriptSegment{
+			Start: secondsToDuration(seg.Start),
+			End:   secondsToDuration(seg.End),
+			Text:  strings.TrimSpace(seg.Text),
+		})
+	}
+	return result, nil
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
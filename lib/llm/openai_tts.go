@@ -11,16 +11,19 @@ import (
 	"strings"
 
 	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/models"
 )
 
 type ttsRequest struct {
-	Model          string `json:"model"`
-	Voice          string `json:"voice"`
-	Input          string `json:"input"`
-	ResponseFormat string `json:"response_format,omitempty"`
+	Model          string  `json:"model"`
+	Voice          string  `json:"voice"`
+	Input          string  `json:"input"`
+	ResponseFormat string  `json:"response_format,omitempty"`
+	Speed          float64 `json:"speed,omitempty"`
+	Instructions   string  `json:"instructions,omitempty"`
 }
 
-func (c *Client) openAITTS(ctx context.Context, provider, voice, text string) ([]byte, error) {
+func (c *Client) openAITTS(ctx context.Context, provider string, voice models.VoiceProfile, text string) ([]byte, error) {
 	apiKey, err := config.GetProviderAPIKey(provider)
 	if err != nil {
 		return nil, err
@@ -42,9 +45,11 @@ func (c *Client) openAITTS(ctx context.Context, provider, voice, text string) ([
 
 	body, err := json.Marshal(ttsRequest{
 		Model:          model,
-		Voice:          voice,
+		Voice:          voice.VoiceID,
 		Input:          text,
-		ResponseFormat: "wav",
+		ResponseFormat: config.GetProviderTTSFormat(provider),
+		Speed:          voice.Speed,
+		Instructions:   voice.Instructions,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("marshal tts request: %w", err)
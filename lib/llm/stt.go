@@ -0,0 +1,46 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nraghuveer/vibecast/lib/config"
+)
+
+// Transcriber converts captured WAV segments into text.
+// Hosted Whisper endpoints and a local whisper.cpp binary both implement it.
+type Transcriber interface {
+	Transcribe(ctx context.Context, wavPath string) (*Transcription, error)
+}
+
+// Transcriber returns the speech-to-text backend configured for provider.
+// Providers with stt_binary set run whisper.cpp locally; all others call the
+// provider's hosted stt_url.
+func (c *Client) Transcriber(provider string) (Transcriber, error) {
+	binary, err := config.GetProviderSTTBinary(provider)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(binary) != "" {
+		return newWhisperCppTranscriber(provider)
+	}
+
+	url, err := config.GetProviderSTTURL(provider)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(url) == "" {
+		return nil, fmt.Errorf("stt not configured for provider %s", provider)
+	}
+	return &openAITranscriber{client: c, provider: provider}, nil
+}
+
+// TranscribeSpeech transcribes a WAV segment with the configured provider.
+func (c *Client) TranscribeSpeech(ctx context.Context, provider, wavPath string) (*Transcription, error) {
+	t, err := c.Transcriber(provider)
+	if err != nil {
+		return nil, err
+	}
+	return t.Transcribe(ctx, wavPath)
+}
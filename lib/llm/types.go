@@ -1,5 +1,7 @@
 package llm
 
+import "time"
+
 // ChatMessage is an OpenAI-compatible chat message.
 // Role should be one of: system, user, assistant.
 type ChatMessage struct {
@@ -9,7 +11,23 @@ type ChatMessage struct {
 
 // StreamEvent represents a streamed token (delta) or terminal event.
 type StreamEvent struct {
-	Delta string
-	Done  bool
-	Err   error
+	Delta        string
+	Done         bool
+	FinishReason string // set on the final event when the provider reports one, e.g. "length"
+	Err          error
+}
+
+// Transcription is the text recognized from a captured audio segment.
+type Transcription struct {
+	Text     string
+	Language string
+	Segments []TranscriptSegment
+}
+
+// TranscriptSegment is a span of recognized speech with offsets relative to
+// the start of the transcribed audio.
+type TranscriptSegment struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
 }
@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/config"
+)
+
+// whisperCppTranscriber runs a local whisper.cpp binary (whisper-cli) against
+// a GGML model. It needs no network access. whisper.cpp expects 16 kHz mono
+// WAV input, which is what voice capture records.
+type whisperCppTranscriber struct {
+	binary   string
+	model    string
+	language string
+	threads  int
+}
+
+func newWhisperCppTranscriber(provider string) (*whisperCppTranscriber, error) {
+	cfg, err := config.GetProviderConfig(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	binary, err := exec.LookPath(cfg.STTBinary)
+	if err != nil {
+		return nil, fmt.Errorf("whisper.cpp binary %q not found: %w", cfg.STTBinary, err)
+	}
+	if strings.TrimSpace(cfg.STTModel) == "" {
+		return nil, fmt.Errorf("stt model not configured for provider %s", provider)
+	}
+	model := config.ExpandPath(cfg.STTModel)
+	if _, err := os.Stat(model); err != nil {
+		return nil, fmt.Errorf("whisper.cpp model %s: %w", model, err)
+	}
+
+	return &whisperCppTranscriber{
+		binary:   binary,
+		model:    model,
+		language: strings.TrimSpace(cfg.STTLanguage),
+		threads:  cfg.STTThreads,
+	}, nil
+}
+
+func (t *whisperCppTranscriber) Transcribe(ctx context.Context, wavPath string) (*Transcription, error) {
+	args := []string{"-m", t.model, "-f", wavPath, "-np"}
+	if t.language != "" {
+		args = append(args, "-l", t.language)
+	}
+	if t.threads > 0 {
+		args = append(args, "-t", strconv.Itoa(t.threads))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.binary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("whisper.cpp failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("whisper.cpp failed: %w", err)
+	}
+
+	result := parseWhisperCppOutput(stdout.String())
+	result.Language = t.language
+	return result, nil
+}
+
+// whisperCppLine matches whisper-cli result lines such as
+// "[00:00:01.240 --> 00:00:03.880]   Thanks for having me."
+var whisperCppLine = regexp.MustCompile(`^\[(\d+:\d{2}:\d{2}[.,]\d{3}) --> (\d+:\d{2}:\d{2}[.,]\d{3})\]\s*(.*)$`)
+
+// parseWhisperCppOutput parses whisper-cli stdout into text plus segment
+// timestamps. Lines without a timestamp prefix (e.g. when run with -nt) are
+// kept as untimed text.
+func parseWhisperCppOutput(output string) *Transcription {
+	result := &Transcription{}
+	var text []string
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		match := whisperCppLine.FindStringSubmatch(line)
+		if match == nil {
+			text = append(text, line)
+			continue
+		}
+
+		segText := strings.TrimSpace(match[3])
+		start, err1 := parseWhisperTimestamp(match[1])
+		end, err2 := parseWhisperTimestamp(match[2])
+		if err1 == nil && err2 == nil {
+			result.Segments = append(result.Segments, TranscriptSegment{
+				Start: start,
+				End:   end,
+				Text:  segText,
+			})
+		}
+		if segText != "" {
+			text = append(text, segText)
+		}
+	}
+
+	result.Text = strings.Join(text, " ")
+	return result
+}
+
+// parseWhisperTimestamp parses "HH:MM:SS.mmm" (or with a comma separator).
+func parseWhisperTimestamp(s string) (time.Duration, error) {
+	parts := strings.Split(strings.Replace(s, ",", ".", 1), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		secondsToDuration(seconds), nil
+}
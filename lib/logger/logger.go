@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/nraghuveer/vibecast/lib/config"
 )
 
 var (
@@ -33,13 +35,7 @@ func newLogger() *Logger {
 	startTime := time.Now()
 
 	// Create logs directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get home directory: %v\n", err)
-		return &Logger{startTime: startTime}
-	}
-
-	logsDir := filepath.Join(homeDir, ".vibecast", "logs")
+	logsDir := config.GetLogsDir()
 	if err := os.MkdirAll(logsDir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to create logs directory: %v\n", err)
 		return &Logger{startTime: startTime}
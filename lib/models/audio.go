@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// AudioFile is a synthesized clip stored in a conversation's audio folder
+type AudioFile struct {
+	ConversationID string
+	Index          int
+	Filename       string
+	Speaker        SpeakerType
+	MessageID      string // transcript message the clip voices; "" if unknown
+	Format         string
+	Duration       time.Duration
+	SampleRate     int
+	Channels       int
+	SizeBytes      int64
+	CreatedAt      time.Time
+}
@@ -3,13 +3,18 @@ package models
 import "time"
 
 type Conversation struct {
-	ID        string
-	Title     string
-	Topic     string
-	Persona   string
-	VoiceID   string
-	VoiceName string
-	Provider  string
-	CreatedAt time.Time
-	EndedAt   *time.Time
+	ID                 string
+	Title              string
+	Topic              string
+	Persona            string
+	VoiceID            string
+	VoiceName          string
+	VoiceProfileID     string
+	HostVoiceProfileID string // "" follows ai.host_voice, "none" leaves host lines silent
+	Provider           string
+	CreatedAt          time.Time
+	EndedAt            *time.Time
+
+	ParentID            string // conversation this was forked from; "" if none
+	ForkedFromMessageID string // last message copied from the parent
 }
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Message is a transcript turn as indexed in the database for search
+type Message struct {
+	ID             string
+	ConversationID string
+	Speaker        SpeakerType
+	Content        string
+	CreatedAt      time.Time
+	TakeOf         string // the first take of the answer, for a retake
+	Alternate      bool   // a take that isn't the canonical one
+}
+
+// MessageMatch is a message found by a search, with the conversation it
+// belongs to and an excerpt around the match
+type MessageMatch struct {
+	Message
+	ConversationTitle string
+	Snippet           string
+}
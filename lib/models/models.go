@@ -8,6 +8,17 @@ type Template struct {
 	Persona string
 }
 
+// VoiceProfile is a TTS voice with optional delivery settings
+type VoiceProfile struct {
+	ID           string
+	Name         string
+	Provider     string
+	VoiceID      string
+	Speed        float64
+	Instructions string
+	Description  string
+}
+
 // SpeakerType represents who is speaking in a conversation
 type SpeakerType int
 
@@ -28,6 +39,17 @@ func (s SpeakerType) String() string {
 	}
 }
 
+// MarshalText encodes the speaker by name, e.g. in transcripts.
+func (s SpeakerType) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a speaker name written by MarshalText.
+func (s *SpeakerType) UnmarshalText(text []byte) error {
+	*s = ParseSpeakerType(string(text))
+	return nil
+}
+
 // ParseSpeakerType converts a string to SpeakerType
 func ParseSpeakerType(s string) SpeakerType {
 	switch s {
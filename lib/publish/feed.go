@@ -0,0 +1,190 @@
+package publish
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nraghuveer/vibecast/lib/config"
+)
+
+const (
+	itunesNamespace  = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+	podcastNamespace = "https://podcastindex.org/namespace/1.0"
+)
+
+// podcastGUIDNamespace is the UUIDv5 namespace defined for podcast:guid.
+var podcastGUIDNamespace = uuid.MustParse("ead4c236-bf58-58c6-a2c6-a6b28d128cb6")
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	ITunes  string     `xml:"xmlns:itunes,attr"`
+	Podcast string     `xml:"xmlns:podcast,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title          string         `xml:"title"`
+	Link           string         `xml:"link,omitempty"`
+	Description    string         `xml:"description"`
+	Language       string         `xml:"language"`
+	Generator      string         `xml:"generator"`
+	LastBuildDate  string         `xml:"lastBuildDate"`
+	ITunesAuthor   string         `xml:"itunes:author,omitempty"`
+	ITunesSummary  string         `xml:"itunes:summary"`
+	ITunesType     string         `xml:"itunes:type"`
+	ITunesExplicit string         `xml:"itunes:explicit"`
+	ITunesImage    *itunesImage   `xml:"itunes:image,omitempty"`
+	ITunesCategory itunesCategory `xml:"itunes:category"`
+	ITunesOwner    *itunesOwner   `xml:"itunes:owner,omitempty"`
+	PodcastGUID    string         `xml:"podcast:guid,omitempty"`
+	PodcastLocked  string         `xml:"podcast:locked"`
+	Items          []rssItem      `xml:"item"`
+}
+
+type itunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+type itunesCategory struct {
+	Text string `xml:"text,attr"`
+}
+
+type itunesOwner struct {
+	Name  string `xml:"itunes:name,omitempty"`
+	Email string `xml:"itunes:email,omitempty"`
+}
+
+type rssItem struct {
+	Title             string          `xml:"title"`
+	Description       cdata           `xml:"description"`
+	GUID              rssGUID         `xml:"guid"`
+	PubDate           string          `xml:"pubDate"`
+	Enclosure         rssEnclosure    `xml:"enclosure"`
+	ITunesDuration    int             `xml:"itunes:duration"`
+	ITunesEpisode     int             `xml:"itunes:episode"`
+	ITunesEpisodeType string          `xml:"itunes:episodeType"`
+	ITunesExplicit    string          `xml:"itunes:explicit"`
+	PodcastChapters   podcastChapters `xml:"podcast:chapters"`
+	PodcastTranscript []podcastTranscript
+}
+
+// cdata keeps multi-line show notes readable in the feed.
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type podcastChapters struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type podcastTranscript struct {
+	XMLName xml.Name `xml:"podcast:transcript"`
+	URL     string   `xml:"url,attr"`
+	Type    string   `xml:"type,attr"`
+}
+
+// writeFeed writes an RSS 2.0 feed with iTunes and Podcasting 2.0 tags,
+// newest episode first.
+func writeFeed(path string, cfg config.PublishConfig, episodes []Episode) error {
+	explicit := "false"
+	if cfg.Explicit {
+		explicit = "true"
+	}
+
+	channel := rssChannel{
+		Title:          cfg.Title,
+		Link:           cfg.BaseURL,
+		Description:    cfg.Description,
+		Language:       cfg.Language,
+		Generator:      "VibeCast",
+		LastBuildDate:  time.Now().Format(time.RFC1123Z),
+		ITunesAuthor:   cfg.Author,
+		ITunesSummary:  cfg.Description,
+		ITunesType:     "episodic",
+		ITunesExplicit: explicit,
+		ITunesCategory: itunesCategory{Text: cfg.Category},
+		PodcastGUID:    feedGUID(cfg.BaseURL),
+		PodcastLocked:  "no",
+	}
+	if cfg.Image != "" {
+		channel.ITunesImage = &itunesImage{Href: cfg.Image}
+	}
+	if cfg.Author != "" || cfg.Email != "" {
+		channel.ITunesOwner = &itunesOwner{Name: cfg.Author, Email: cfg.Email}
+	}
+
+	for i := len(episodes) - 1; i >= 0; i-- {
+		ep := episodes[i]
+		channel.Items = append(channel.Items, rssItem{
+			Title:             ep.Title,
+			Description:       cdata{Value: ep.ShowNotes},
+			GUID:              rssGUID{IsPermaLink: "false", Value: ep.ID},
+			PubDate:           ep.Published.Format(time.RFC1123Z),
+			Enclosure:         rssEnclosure{URL: publicURL(cfg.BaseURL, ep.Audio), Length: ep.AudioBytes, Type: ep.AudioType},
+			ITunesDuration:    ep.DurationSeconds,
+			ITunesEpisode:     ep.Number,
+			ITunesEpisodeType: "full",
+			ITunesExplicit:    explicit,
+			PodcastChapters:   podcastChapters{URL: publicURL(cfg.BaseURL, ep.Chapters), Type: "application/json+chapters"},
+			PodcastTranscript: []podcastTranscript{
+				{URL: publicURL(cfg.BaseURL, ep.CaptionsVTT), Type: "text/vtt"},
+				{URL: publicURL(cfg.BaseURL, ep.CaptionsSRT), Type: "application/x-subrip"},
+			},
+		})
+	}
+
+	data, err := xml.MarshalIndent(rssFeed{
+		Version: "2.0",
+		ITunes:  itunesNamespace,
+		Podcast: podcastNamespace,
+		Channel: channel,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode feed: %w", err)
+	}
+
+	return writeFileAtomic(path, append([]byte(xml.Header), append(data, '\n')...))
+}
+
+// publicURL joins a path inside the publish directory onto base_url.
+// Without a base URL the path is left relative.
+func publicURL(baseURL, path string) string {
+	if baseURL == "" {
+		return path
+	}
+	u, err := url.JoinPath(baseURL, path)
+	if err != nil {
+		return strings.TrimRight(baseURL, "/") + "/" + path
+	}
+	return u
+}
+
+// feedGUID derives podcast:guid from the feed URL, as the namespace spec
+// requires: a UUIDv5 of the URL without scheme and trailing slashes.
+func feedGUID(baseURL string) string {
+	if baseURL == "" {
+		return ""
+	}
+	feedURL := publicURL(baseURL, feedFileName)
+	if i := strings.Index(feedURL, "://"); i >= 0 {
+		feedURL = feedURL[i+3:]
+	}
+	return uuid.NewSHA1(podcastGUIDNamespace, []byte(strings.TrimRight(feedURL, "/"))).String()
+}
@@ -0,0 +1,410 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/atomicfile"
+	"github.com/nraghuveer/vibecast/lib/audio"
+	"github.com/nraghuveer/vibecast/lib/captions"
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/episode"
+	"github.com/nraghuveer/vibecast/lib/models"
+	"github.com/nraghuveer/vibecast/lib/storage"
+)
+
+const (
+	feedFileName    = "feed.xml"
+	episodesDirName = "episodes"
+
+	// Show notes list at most this many of the host's questions.
+	maxNoteQuestions = 12
+	maxTitleLength   = 80
+)
+
+// mimeTypes are the enclosure types for each publishable format.
+var mimeTypes = map[string]string{
+	audio.FormatMP3:  "audio/mpeg",
+	audio.FormatOpus: "audio/ogg",
+	audio.FormatFLAC: "audio/flac",
+	audio.FormatWAV:  "audio/wav",
+}
+
+// Episode is the metadata written next to each published audio file.
+type Episode struct {
+	ID              string    `json:"id"`
+	Number          int       `json:"number"`
+	Title           string    `json:"title"`
+	Topic           string    `json:"topic"`
+	Guest           string    `json:"guest"`
+	ShowNotes       string    `json:"show_notes"`
+	Published       time.Time `json:"published"`
+	DurationSeconds int       `json:"duration_seconds"`
+	Audio           string    `json:"audio"` // relative to the publish directory
+	AudioType       string    `json:"audio_type"`
+	AudioBytes      int64     `json:"audio_bytes"`
+	Chapters        string    `json:"chapters"`     // relative to the publish directory
+	CaptionsSRT     string    `json:"captions_srt"` // relative to the publish directory
+	CaptionsVTT     string    `json:"captions_vtt"` // relative to the publish directory
+}
+
+// Result describes a published directory.
+type Result struct {
+	Dir      string
+	Feed     string
+	Episodes []Episode
+	Skipped  []string // conversations that weren't published, with the reason
+	Warnings []string
+}
+
+// Publish renders every ended conversation and writes a static podcast
+// directory: episode audio, per-episode metadata and chapters, and feed.xml.
+// An episode that fails to render keeps the files published earlier; files
+// for conversations that no longer qualify are removed. Episode numbers are
+// kept from run to run, with new episodes numbered after the last.
+func Publish(ctx context.Context, database *db.DB, cfg config.PublishConfig) (*Result, error) {
+	conversations, err := database.GetAllConversations()
+	if err != nil {
+		return nil, err
+	}
+
+	episodesDir := filepath.Join(cfg.Dir, episodesDirName)
+	if err := os.MkdirAll(episodesDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create publish directory: %w", err)
+	}
+
+	result := &Result{Dir: cfg.Dir, Feed: filepath.Join(cfg.Dir, feedFileName)}
+	if strings.TrimSpace(cfg.BaseURL) == "" {
+		result.Warnings = append(result.Warnings, "publish.base_url is not set; enclosure URLs are relative and most podcast apps will reject them")
+	}
+
+	format := strings.ToLower(cfg.Format)
+	if _, ok := mimeTypes[format]; !ok {
+		return nil, fmt.Errorf("unsupported publish format %q", cfg.Format)
+	}
+	if format != audio.FormatWAV && audio.FFmpegPath() == "" {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("ffmpeg not found; publishing wav instead of %s", format))
+		format = audio.FormatWAV
+	}
+
+	// Oldest first, so episode numbers follow recording order.
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].CreatedAt.Before(conversations[j].CreatedAt)
+	})
+
+	published := map[string]*Episode{}
+	next := 1
+	for _, c := range conversations {
+		if ep, ok := publishedEpisode(cfg.Dir, c.ID); ok && c.EndedAt.Valid {
+			published[c.ID] = ep
+			next = max(next, ep.Number+1)
+		}
+	}
+
+	keep := map[string]bool{}
+	for _, c := range conversations {
+		if !c.EndedAt.Valid {
+			continue
+		}
+		keep[c.ID] = true
+
+		previous := published[c.ID]
+		ep, err := publishConversation(ctx, database, c, episodesDir, format)
+		if err != nil && previous != nil {
+			// A conversation whose clips were pruned can never be rendered
+			// again, so keeping its episode isn't worth a warning.
+			// Older prunes left no mark, only an empty clip index.
+			if count, countErr := database.CountAudioFiles(c.ID); !c.AudioPrunedAt.Valid && (countErr != nil || count > 0) {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s (%s): %v; kept the episode published earlier", c.ID, c.Title, err))
+			}
+			ep, err = previous, nil
+		}
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s (%s): %v", c.ID, c.Title, err))
+			continue
+		}
+		if previous != nil && previous.Number > 0 {
+			ep.Number = previous.Number
+		} else {
+			ep.Number = next
+			next++
+		}
+		if err := writeJSON(filepath.Join(episodesDir, c.ID+".json"), ep); err != nil {
+			return result, err
+		}
+		keep[c.ID] = true
+		result.Episodes = append(result.Episodes, *ep)
+	}
+
+	if err := prune(episodesDir, keep); err != nil {
+		return result, err
+	}
+	if err := writeFeed(result.Feed, cfg, result.Episodes); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func publishConversation(ctx context.Context, database *db.DB, c db.Conversation, episodesDir, format string) (*Episode, error) {
+	opts := episode.OptionsFromConfig()
+	if format != audio.FormatWAV && !containsFold(opts.Formats, format) {
+		opts.Formats = append(opts.Formats, format)
+	}
+
+	rendered, err := episode.RenderConversation(ctx, database, c.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	source := ""
+	for _, path := range rendered.Files {
+		if strings.EqualFold(strings.TrimPrefix(filepath.Ext(path), "."), format) {
+			source = path
+		}
+	}
+	if source == "" {
+		return nil, fmt.Errorf("no %s rendering: %s", format, strings.Join(rendered.Skipped, "; "))
+	}
+
+	// Renders are sealed when encryption is on; published audio never is.
+	plain, cleanup, err := crypt.PlainFile(source)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	audioName := c.ID + "." + format
+	size, err := copyFile(plain, filepath.Join(episodesDir, audioName))
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := storage.LoadMessages(c.ID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := database.GetAudioFiles(c.ID)
+	if err != nil {
+		return nil, err
+	}
+	files = storage.CanonicalAudio(messages, files)
+	messages = storage.Canonical(messages)
+
+	chaptersName := c.ID + ".chapters.json"
+	if err := writeJSON(filepath.Join(episodesDir, chaptersName), buildChapters(messages, files, rendered.Timeline)); err != nil {
+		return nil, err
+	}
+
+	cues := captions.Build(rendered.Timeline, messages, nil)
+	srtName := c.ID + ".srt"
+	vttName := c.ID + ".vtt"
+	if err := captions.WritePublicFile(filepath.Join(episodesDir, srtName), cues); err != nil {
+		return nil, err
+	}
+	if err := captions.WritePublicFile(filepath.Join(episodesDir, vttName), cues); err != nil {
+		return nil, err
+	}
+
+	return &Episode{
+		ID:              c.ID,
+		Title:           c.Title,
+		Topic:           c.Topic,
+		Guest:           c.Persona,
+		ShowNotes:       ShowNotes(c, messages),
+		Published:       c.EndedAt.Time,
+		DurationSeconds: int(rendered.Duration.Round(time.Second) / time.Second),
+		Audio:           episodesDirName + "/" + audioName,
+		AudioType:       mimeTypes[format],
+		AudioBytes:      size,
+		Chapters:        episodesDirName + "/" + chaptersName,
+		CaptionsSRT:     episodesDirName + "/" + srtName,
+		CaptionsVTT:     episodesDirName + "/" + vttName,
+	}, nil
+}
+
+// ShowNotes describes an episode from its topic, guest and the host's questions.
+func ShowNotes(c db.Conversation, messages []storage.Message) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(c.Topic))
+	if persona := strings.TrimSpace(c.Persona); persona != "" {
+		fmt.Fprintf(&b, "\n\nGuest: %s", persona)
+	}
+
+	var questions []string
+	for _, m := range messages {
+		if m.Speaker == models.HOST && strings.TrimSpace(m.Content) != "" {
+			questions = append(questions, truncate(m.Content, 200))
+		}
+	}
+	if len(questions) > 0 {
+		b.WriteString("\n\nIn this episode:")
+		for i, q := range questions {
+			if i == maxNoteQuestions {
+				fmt.Fprintf(&b, "\n- ...and %d more", len(questions)-i)
+				break
+			}
+			fmt.Fprintf(&b, "\n- %s", q)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Chapters is a Podcasting 2.0 chapters file.
+type Chapters struct {
+	Version  string    `json:"version"`
+	Chapters []Chapter `json:"chapters"`
+}
+
+type Chapter struct {
+	StartTime float64 `json:"startTime"`
+	Title     string  `json:"title"`
+}
+
+// buildChapters starts a chapter at each host question. When every question
+// was voiced, chapters begin at the host's clips; otherwise at the first clip
+// recorded after the question was asked. files and timeline are in clip order.
+func buildChapters(messages []storage.Message, files []models.AudioFile, timeline []episode.Placement) Chapters {
+	chapters := Chapters{Version: "1.2.0", Chapters: []Chapter{{StartTime: 0, Title: "Introduction"}}}
+
+	var questions []storage.Message
+	for _, m := range messages {
+		if m.Speaker == models.HOST {
+			questions = append(questions, m)
+		}
+	}
+	if len(questions) == 0 || len(timeline) == 0 {
+		return chapters
+	}
+
+	var starts []time.Duration
+	for i, p := range timeline {
+		if p.Clip.Speaker == models.HOST && (i == 0 || timeline[i-1].Clip.Speaker != models.HOST) {
+			starts = append(starts, p.Start)
+		}
+	}
+
+	if len(starts) != len(questions) {
+		starts = starts[:0]
+		next := 0
+		for _, q := range questions {
+			asked := q.Timestamp.Truncate(time.Second)
+			for next < len(files) && next < len(timeline) && files[next].CreatedAt.Before(asked) {
+				next++
+			}
+			if next >= len(timeline) {
+				break
+			}
+			starts = append(starts, timeline[next].Start)
+		}
+	}
+
+	for i, start := range starts {
+		seconds := start.Seconds()
+		last := chapters.Chapters[len(chapters.Chapters)-1]
+		if seconds <= last.StartTime {
+			continue
+		}
+		chapters.Chapters = append(chapters.Chapters, Chapter{
+			StartTime: float64(start.Milliseconds()) / 1000,
+			Title:     truncate(questions[i].Content, maxTitleLength),
+		})
+	}
+	return chapters
+}
+
+// prune removes published files for conversations that are no longer published.
+func prune(episodesDir string, keep map[string]bool) error {
+	entries, err := os.ReadDir(episodesDir)
+	if err != nil {
+		return fmt.Errorf("failed to read publish directory: %w", err)
+	}
+	for _, entry := range entries {
+		id, _, _ := strings.Cut(entry.Name(), ".")
+		if entry.IsDir() || keep[id] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(episodesDir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to remove stale episode file: %w", err)
+		}
+	}
+	return nil
+}
+
+// publishedEpisode returns the episode published for a conversation by an
+// earlier run, if its metadata and audio are still there.
+func publishedEpisode(dir, conversationID string) (*Episode, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, episodesDirName, conversationID+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var ep Episode
+	if err := json.Unmarshal(data, &ep); err != nil {
+		return nil, false
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(ep.Audio))); err != nil {
+		return nil, false
+	}
+	return &ep, true
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	return writeFileAtomic(path, append(data, '\n'))
+}
+
+// writeFileAtomic writes to a temp file and renames it into place, so a
+// static host never serves a half-written file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := atomicfile.Write(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func copyFile(src, dst string) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open episode audio: %w", err)
+	}
+	defer in.Close()
+
+	var n int64
+	err = atomicfile.WriteFunc(dst, 0644, func(w io.Writer) error {
+		n, err = io.Copy(w, in)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy published audio: %w", err)
+	}
+	return n, nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
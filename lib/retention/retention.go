@@ -0,0 +1,202 @@
+// Package retention prunes conversation clips so audio doesn't pile up
+// forever. Clips go one at a time, oldest first; transcripts and rendered
+// episodes are kept, and starred conversations are never pruned.
+package retention
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/storage"
+)
+
+// Policy bounds the disk used by clips. Zero fields are no limit.
+type Policy struct {
+	// MaxAge prunes each clip once it is older.
+	MaxAge time.Duration
+	// MaxBytes prunes the oldest clips, across conversations, until all
+	// clips fit.
+	MaxBytes int64
+}
+
+// ConfiguredPolicy returns the policy set under retention in the config.
+func ConfiguredPolicy() Policy {
+	cfg := config.GetRetentionConfig()
+	return Policy{MaxAge: cfg.MaxAge(), MaxBytes: cfg.MaxBytes()}
+}
+
+// Enabled reports whether the policy prunes anything.
+func (p Policy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxBytes > 0
+}
+
+// Reason says which limit a conversation's clips were pruned for.
+type Reason string
+
+const (
+	ReasonAge   Reason = "age"
+	ReasonQuota Reason = "quota"
+)
+
+// Pruned is a conversation some or all of whose clips were removed for
+// one reason.
+type Pruned struct {
+	ConversationID string
+	Title          string
+	Newest         time.Time // when the newest clip removed was written
+	Clips          int
+	Bytes          int64
+	Kept           int // clips it still has
+	Reason         Reason
+}
+
+// Report describes a run.
+type Report struct {
+	Pruned    []Pruned
+	Clips     int   // clips removed
+	Reclaimed int64 // bytes freed
+	Remaining int64 // clip bytes left
+	Starred   int   // starred conversations with clips, left alone
+}
+
+// clip is a clip on disk in a conversation that may be pruned.
+type clip struct {
+	conv  *db.Conversation
+	name  string
+	bytes int64
+	mtime time.Time
+}
+
+// Run applies policy to the clips of every conversation, oldest clip first:
+// a clip goes when it is past the age limit, or while all clips exceed the
+// quota. With dryRun nothing is removed and the report says what would be.
+func Run(database *db.DB, policy Policy, dryRun bool) (Report, error) {
+	var report Report
+
+	conversations, err := database.GetAllConversations()
+	if err != nil {
+		return report, err
+	}
+
+	var clips []clip
+	counts := map[string]int{}
+	for i := range conversations {
+		c := &conversations[i]
+		found, err := scan(c)
+		if err != nil {
+			return report, err
+		}
+		if len(found) == 0 {
+			continue
+		}
+		for _, cl := range found {
+			report.Remaining += cl.bytes
+		}
+		if c.StarredAt.Valid {
+			report.Starred++
+			continue
+		}
+		clips = append(clips, found...)
+		counts[c.ID] = len(found)
+	}
+	sort.SliceStable(clips, func(i, j int) bool {
+		return clips[i].mtime.Before(clips[j].mtime)
+	})
+
+	cutoff := time.Now().Add(-policy.MaxAge)
+	pruned := map[string]bool{}
+	type entry struct {
+		id     string
+		reason Reason
+	}
+	entries := map[entry]int{} // index in report.Pruned
+	for _, cl := range clips {
+		var reason Reason
+		switch {
+		case policy.MaxAge > 0 && cl.mtime.Before(cutoff):
+			reason = ReasonAge
+		case policy.MaxBytes > 0 && report.Remaining > policy.MaxBytes:
+			reason = ReasonQuota
+		default:
+			continue
+		}
+
+		if !dryRun {
+			if !pruned[cl.conv.ID] {
+				// Marked first, so a conversation never loses clips
+				// without being flagged as incomplete.
+				if err := database.MarkAudioPruned(cl.conv.ID, time.Now()); err != nil {
+					return report, err
+				}
+				pruned[cl.conv.ID] = true
+			}
+			if err := prune(database, cl); err != nil {
+				return report, err
+			}
+		}
+
+		key := entry{cl.conv.ID, reason}
+		i, ok := entries[key]
+		if !ok {
+			i = len(report.Pruned)
+			entries[key] = i
+			report.Pruned = append(report.Pruned, Pruned{
+				ConversationID: cl.conv.ID,
+				Title:          cl.conv.Title,
+				Reason:         reason,
+			})
+		}
+		p := &report.Pruned[i]
+		p.Newest = cl.mtime
+		p.Clips++
+		p.Bytes += cl.bytes
+		counts[cl.conv.ID]--
+		report.Clips++
+		report.Reclaimed += cl.bytes
+		report.Remaining -= cl.bytes
+	}
+	for i := range report.Pruned {
+		report.Pruned[i].Kept = counts[report.Pruned[i].ConversationID]
+	}
+	return report, nil
+}
+
+// scan lists the clips in a conversation's audio directory.
+func scan(c *db.Conversation) ([]clip, error) {
+	audioDir, err := storage.GetAudioDir(c.ID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(audioDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read audio directory: %w", err)
+	}
+
+	var clips []clip
+	for _, entry := range entries {
+		if entry.IsDir() || !storage.IsClipName(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		clips = append(clips, clip{conv: c, name: entry.Name(), bytes: info.Size(), mtime: info.ModTime()})
+	}
+	return clips, nil
+}
+
+// prune deletes a clip and its index record.
+func prune(database *db.DB, cl clip) error {
+	if err := storage.DeleteAudioFile(cl.conv.ID, cl.name); err != nil {
+		return err
+	}
+	return database.DeleteAudioFileRecord(cl.conv.ID, cl.name)
+}
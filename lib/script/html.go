@@ -0,0 +1,157 @@
+package script
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/nraghuveer/vibecast/lib/audio"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+)
+
+// audioTypes are the MIME types of embedded clips, by extension.
+var audioTypes = map[string]string{
+	audio.FormatMP3:  "audio/mpeg",
+	audio.FormatOpus: "audio/ogg",
+	audio.FormatFLAC: "audio/flac",
+	audio.FormatWAV:  "audio/wav",
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { max-width: 46rem; margin: 2rem auto; padding: 0 1rem; font: 16px/1.6 system-ui, sans-serif; color: #222; background: #fdfdfd; }
+h1 { margin-bottom: .5rem; }
+dl.meta { display: grid; grid-template-columns: max-content 1fr; gap: .25rem 1rem; margin: 0 0 2rem; color: #555; }
+dl.meta dt { font-weight: 600; }
+dl.meta dd { margin: 0; }
+.turn { margin: 0 0 1.25rem; padding: .75rem 1rem; border-left: 4px solid; border-radius: 4px; }
+.turn.host { border-color: #7d56f4; background: #f5f2fe; }
+.turn.guest { border-color: #04b575; background: #effaf5; }
+.turn header { display: flex; gap: .75rem; align-items: baseline; }
+.speaker { font-weight: 700; }
+.host .speaker { color: #5a3bc4; }
+.guest .speaker { color: #03804f; }
+time { color: #888; font-size: .85em; font-variant-numeric: tabular-nums; }
+.turn p { margin: .4rem 0; white-space: pre-wrap; }
+audio { display: block; width: 100%; margin-top: .4rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<dl class="meta">
+{{- with .Topic}}
+<dt>Topic</dt><dd>{{.}}</dd>
+{{- end}}
+{{- with .Persona}}
+<dt>Guest</dt><dd>{{.}}</dd>
+{{- end}}
+<dt>Date</dt><dd>{{.Date}}</dd>
+<dt>Duration</dt><dd>{{.Duration}}</dd>
+</dl>
+{{- range .Turns}}
+<section class="turn {{.Class}}">
+<header><span class="speaker">{{.Speaker}}</span> <time>{{.Offset}}</time></header>
+{{- range .Paragraphs}}
+<p>{{.}}</p>
+{{- end}}
+{{- range .Audio}}
+<audio controls preload="none" src="{{.}}"></audio>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
+
+type page struct {
+	Title    string
+	Topic    string
+	Persona  string
+	Date     string
+	Duration string
+	Turns    []turn
+}
+
+type turn struct {
+	Class      string
+	Speaker    string
+	Offset     string
+	Paragraphs []string
+	Audio      []template.URL
+}
+
+// HTML formats doc as a standalone page with a player for each clip. Clips
+// are embedded unless opts.LinkAudio is set.
+func HTML(doc *Document, opts Options) ([]byte, error) {
+	p := page{
+		Title:    oneLine(doc.Title),
+		Topic:    oneLine(doc.Topic),
+		Persona:  oneLine(doc.Persona),
+		Date:     doc.Date.Format("January 2, 2006"),
+		Duration: length(doc.Duration),
+	}
+	for _, line := range doc.Lines {
+		t := turn{
+			Class:   strings.ToLower(line.Speaker.String()),
+			Speaker: line.Speaker.String(),
+			Offset:  clock(line.Offset),
+		}
+		for _, paragraph := range strings.Split(line.Text, "\n\n") {
+			if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+				t.Paragraphs = append(t.Paragraphs, paragraph)
+			}
+		}
+		for _, clip := range line.Clips {
+			src, err := audioSource(clip, opts)
+			if err != nil {
+				return nil, err
+			}
+			t.Audio = append(t.Audio, src)
+		}
+		p.Turns = append(p.Turns, t)
+	}
+
+	var b bytes.Buffer
+	if err := pageTemplate.Execute(&b, p); err != nil {
+		return nil, fmt.Errorf("failed to render page: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// audioSource returns a clip's player source: a data URL, or with
+// opts.LinkAudio a file URL.
+func audioSource(path string, opts Options) (template.URL, error) {
+	if opts.LinkAudio {
+		sealed, err := crypt.IsSealedFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read audio file: %w", err)
+		}
+		if sealed {
+			return "", fmt.Errorf("can't link to %s: the clips are encrypted; embed them instead", filepath.Base(path))
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve audio path: %w", err)
+		}
+		return template.URL((&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()), nil
+	}
+
+	data, err := crypt.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read audio file: %w", err)
+	}
+	mimeType := audioTypes[strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))]
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return template.URL("data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data)), nil
+}
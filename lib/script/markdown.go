@@ -0,0 +1,26 @@
+package script
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Markdown formats doc as a script: the metadata as a list, then each turn
+// under its speaker and offset.
+func Markdown(doc *Document) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", oneLine(doc.Title))
+	if doc.Topic != "" {
+		fmt.Fprintf(&b, "- **Topic:** %s\n", oneLine(doc.Topic))
+	}
+	if doc.Persona != "" {
+		fmt.Fprintf(&b, "- **Guest:** %s\n", oneLine(doc.Persona))
+	}
+	fmt.Fprintf(&b, "- **Date:** %s\n", doc.Date.Format("January 2, 2006"))
+	fmt.Fprintf(&b, "- **Duration:** %s\n", length(doc.Duration))
+
+	for _, line := range doc.Lines {
+		fmt.Fprintf(&b, "\n**%s** · %s\n\n%s\n", line.Speaker, clock(line.Offset), line.Text)
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,192 @@
+// Package script writes a conversation as a readable document: a Markdown
+// script, a standalone HTML page or plain text.
+package script
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/bundle"
+	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/models"
+	"github.com/nraghuveer/vibecast/lib/storage"
+)
+
+// Document formats, named by their file extensions.
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+	FormatText     = "txt"
+)
+
+// Formats lists the document formats.
+var Formats = []string{FormatMarkdown, FormatHTML, FormatText}
+
+// Document is a conversation prepared for export.
+type Document struct {
+	Title    string
+	Topic    string
+	Persona  string
+	Date     time.Time
+	Duration time.Duration
+	Lines    []Line
+}
+
+// Line is one turn of the conversation.
+type Line struct {
+	Offset  time.Duration // from the first turn
+	Speaker models.SpeakerType
+	Text    string
+	Clips   []string // paths of the clips voicing it that are still on disk
+}
+
+// Options controls how a document is written.
+type Options struct {
+	// LinkAudio makes the HTML page point at the clip files instead of
+	// embedding them, keeping it small but tied to this machine.
+	LinkAudio bool
+}
+
+// Load reads a conversation's canonical takes and the clips voicing them.
+func Load(database *db.DB, conversationID string) (*Document, error) {
+	c, err := database.GetConversation(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := storage.LoadMessages(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := database.GetAudioFiles(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	audioDir, err := storage.GetAudioDir(conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	links := storage.InferAudioLinks(messages, files)
+	files = storage.CanonicalAudio(messages, files)
+	messages = storage.Canonical(messages)
+
+	clips := map[string][]string{}
+	for _, f := range files {
+		messageID := f.MessageID
+		if messageID == "" {
+			messageID = links[f.Filename]
+		}
+		path := filepath.Join(audioDir, f.Filename)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		clips[messageID] = append(clips[messageID], path)
+	}
+
+	doc := &Document{
+		Title:   c.Title,
+		Topic:   c.Topic,
+		Persona: c.Persona,
+		Date:    c.CreatedAt,
+	}
+	if len(messages) == 0 {
+		return doc, nil
+	}
+
+	start := messages[0].Timestamp
+	if started := messages[0].StartedAt; started != nil && started.Before(start) {
+		start = *started
+	}
+	end := messages[len(messages)-1].Timestamp
+	if c.EndedAt.Valid && c.EndedAt.Time.After(end) {
+		end = c.EndedAt.Time
+	}
+	doc.Duration = end.Sub(start)
+
+	for _, msg := range messages {
+		doc.Lines = append(doc.Lines, Line{
+			Offset:  max(msg.Timestamp.Sub(start), 0),
+			Speaker: msg.Speaker,
+			Text:    strings.TrimSpace(msg.Content),
+			Clips:   clips[msg.ID],
+		})
+	}
+	return doc, nil
+}
+
+// Render formats doc as format.
+func Render(doc *Document, format string, opts Options) ([]byte, error) {
+	switch format {
+	case FormatMarkdown:
+		return Markdown(doc), nil
+	case FormatHTML:
+		return HTML(doc, opts)
+	case FormatText:
+		return Text(doc), nil
+	default:
+		return nil, fmt.Errorf("unsupported document format %q", format)
+	}
+}
+
+// FormatFor returns the document format named by path's extension, or ""
+// if it names none. ".markdown", ".htm" and ".text" are accepted too.
+func FormatFor(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return FormatMarkdown
+	case ".html", ".htm":
+		return FormatHTML
+	case ".txt", ".text":
+		return FormatText
+	default:
+		return ""
+	}
+}
+
+// DefaultFileName names a document after the conversation title and id,
+// like a bundle.
+func DefaultFileName(c db.Conversation, format string) string {
+	return strings.TrimSuffix(bundle.DefaultFileName(c), bundle.Extension) + "." + format
+}
+
+// WriteFile writes doc to path as format.
+func WriteFile(doc *Document, path, format string, opts Options) error {
+	data, err := Render(doc, format, opts)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write document: %w", err)
+	}
+	return nil
+}
+
+// clock formats an offset as M:SS, or H:MM:SS past an hour.
+func clock(d time.Duration) string {
+	s := int(d / time.Second)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// length formats a duration for the metadata, e.g. "1h 4m" or "12m 30s".
+func length(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+}
+
+// oneLine collapses whitespace so multi-line settings fit a metadata line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,60 @@
+package script
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// textWidth is where the plain-text script wraps.
+const textWidth = 72
+
+// Text formats doc as a plain-text script, wrapped for reading in any
+// editor or terminal.
+func Text(doc *Document) []byte {
+	var b bytes.Buffer
+	title := oneLine(doc.Title)
+	fmt.Fprintf(&b, "%s\n%s\n\n", title, strings.Repeat("=", len([]rune(title))))
+	if doc.Topic != "" {
+		b.WriteString(wrapText("Topic: "+oneLine(doc.Topic), textWidth))
+	}
+	if doc.Persona != "" {
+		b.WriteString(wrapText("Guest: "+oneLine(doc.Persona), textWidth))
+	}
+	fmt.Fprintf(&b, "Date: %s\n", doc.Date.Format("January 2, 2006"))
+	fmt.Fprintf(&b, "Duration: %s\n", length(doc.Duration))
+
+	for _, line := range doc.Lines {
+		fmt.Fprintf(&b, "\n%s [%s]\n", strings.ToUpper(line.Speaker.String()), clock(line.Offset))
+		for _, paragraph := range strings.Split(line.Text, "\n") {
+			if strings.TrimSpace(paragraph) == "" {
+				b.WriteString("\n")
+				continue
+			}
+			b.WriteString(wrapText(paragraph, textWidth))
+		}
+	}
+	return b.Bytes()
+}
+
+// wrapText breaks a paragraph into lines of at most width characters,
+// except for single words that are longer.
+func wrapText(paragraph string, width int) string {
+	var b strings.Builder
+	lineLen := 0
+	for _, word := range strings.Fields(paragraph) {
+		n := len([]rune(word))
+		if lineLen > 0 && lineLen+1+n > width {
+			b.WriteString("\n")
+			lineLen = 0
+		}
+		if lineLen > 0 {
+			b.WriteString(" ")
+			lineLen++
+		}
+		b.WriteString(word)
+		lineLen += n
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -5,13 +5,27 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/audio"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+	"github.com/nraghuveer/vibecast/lib/models"
 )
 
 var (
 	audioMutexes sync.Map
 )
 
+// AudioIndex records saved clips and hands out their sequence numbers.
+// *db.DB implements it; without one, SaveAudio falls back to scanning the
+// audio directory.
+type AudioIndex interface {
+	NextAudioIndex(conversationID string) (int, error)
+	RecordAudioFile(f models.AudioFile) error
+}
+
 func getAudioMutex(id string) *sync.Mutex {
 	mu, _ := audioMutexes.LoadOrStore(id, &sync.Mutex{})
 	return mu.(*sync.Mutex)
@@ -46,18 +60,8 @@ func GetNextAudioIndex(conversationID string) (int, error) {
 			continue
 		}
 
-		name := entry.Name()
-		if len(name) < 5 {
-			continue
-		}
-
-		indexStr := name[:3]
-		if name[3:] != ".wav" {
-			continue
-		}
-
-		index, err := strconv.Atoi(indexStr)
-		if err != nil {
+		index, ok := parseAudioIndex(entry.Name())
+		if !ok {
 			continue
 		}
 
@@ -69,33 +73,218 @@ func GetNextAudioIndex(conversationID string) (int, error) {
 	return maxIndex + 1, nil
 }
 
-func SaveAudio(conversationID string, audioData []byte) (string, error) {
+// AudioFileName returns the clip filename for an index, e.g. "007.mp3".
+// Indexes past 999 simply grow wider ("1000.wav").
+func AudioFileName(index int, format string) string {
+	return fmt.Sprintf("%03d.%s", index, format)
+}
+
+// IsClipName reports whether name is a clip filename made by AudioFileName.
+func IsClipName(name string) bool {
+	_, ok := parseAudioIndex(name)
+	return ok
+}
+
+// parseAudioIndex extracts the sequence number from a clip filename.
+func parseAudioIndex(name string) (int, bool) {
+	stem, ext, ok := strings.Cut(name, ".")
+	if !ok || ext == "" || stem == "" {
+		return 0, false
+	}
+	index, err := strconv.Atoi(stem)
+	if err != nil || index <= 0 {
+		return 0, false
+	}
+	return index, true
+}
+
+// SaveAudio writes a clip spoken by speaker to the conversation's audio
+// directory and records it in the index, linked to the transcript message
+// it voices. The format is sniffed from the data,
+// falling back to the requested format when the bytes aren't recognized.
+func SaveAudio(index AudioIndex, conversationID, messageID string, speaker models.SpeakerType, audioData []byte, format string) (models.AudioFile, error) {
 	mu := getAudioMutex(conversationID)
 	mu.Lock()
 	defer mu.Unlock()
 
 	audioDir, err := GetAudioDir(conversationID)
 	if err != nil {
-		return "", err
+		return models.AudioFile{}, err
 	}
 
 	if err := os.MkdirAll(audioDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create audio directory: %w", err)
+		return models.AudioFile{}, fmt.Errorf("failed to create audio directory: %w", err)
+	}
+
+	if detected := audio.DetectFormat(audioData); detected != "" {
+		format = detected
+	}
+	if format == "" {
+		format = audio.FormatWAV
 	}
 
-	index, err := GetNextAudioIndex(conversationID)
+	var next int
+	if index != nil {
+		next, err = index.NextAudioIndex(conversationID)
+	} else {
+		next, err = GetNextAudioIndex(conversationID)
+	}
 	if err != nil {
-		return "", err
+		return models.AudioFile{}, err
 	}
 
-	filename := fmt.Sprintf("%03d.wav", index)
-	filepath := filepath.Join(audioDir, filename)
+	// Never overwrite a clip the index doesn't know about yet.
+	filename := AudioFileName(next, format)
+	for {
+		if _, err := os.Stat(filepath.Join(audioDir, filename)); os.IsNotExist(err) {
+			break
+		}
+		next++
+		filename = AudioFileName(next, format)
+	}
+
+	if err := crypt.WriteFile(filepath.Join(audioDir, filename), audioData, 0644); err != nil {
+		return models.AudioFile{}, fmt.Errorf("failed to write audio file: %w", err)
+	}
+
+	file := newAudioFile(conversationID, next, filename, audioData)
+	file.Speaker = speaker
+	file.MessageID = messageID
+	if index != nil {
+		if err := index.RecordAudioFile(file); err != nil {
+			return file, err
+		}
+	}
+
+	return file, nil
+}
+
+// IndexAudioDir records every clip already on disk for a conversation.
+// It backfills conversations whose audio predates the index; those clips
+// were all guest speech.
+func IndexAudioDir(index AudioIndex, conversationID string) (int, error) {
+	names, err := ListAudioFiles(conversationID)
+	if err != nil {
+		return 0, err
+	}
+
+	return IndexAudioFiles(index, conversationID, names)
+}
+
+// IndexAudioFiles records the named clips of a conversation as guest speech,
+// replacing any existing rows for them. Names that aren't clips are skipped.
+func IndexAudioFiles(index AudioIndex, conversationID string, names []string) (int, error) {
+	audioDir, err := GetAudioDir(conversationID)
+	if err != nil {
+		return 0, err
+	}
+
+	indexed := 0
+	for _, name := range names {
+		n, ok := parseAudioIndex(name)
+		if !ok {
+			continue
+		}
+		data, err := crypt.ReadFile(filepath.Join(audioDir, name))
+		if err != nil {
+			return indexed, fmt.Errorf("failed to read audio file: %w", err)
+		}
+		if err := index.RecordAudioFile(newAudioFile(conversationID, n, name, data)); err != nil {
+			return indexed, err
+		}
+		indexed++
+	}
+
+	return indexed, nil
+}
+
+// InferAudioLinks guesses the message each unlinked clip voices, for clips
+// saved before clips were linked. A transcript audio reference wins;
+// otherwise a host clip belongs to the last host message recorded before
+// it, and a guest clip to the guest answer that followed that host message,
+// since guest answers are recorded only once they finish streaming. The
+// result maps clip filenames to message IDs.
+func InferAudioLinks(messages []Message, files []models.AudioFile) map[string]string {
+	links := map[string]string{}
+	for _, msg := range messages {
+		for _, name := range msg.Audio {
+			links[name] = msg.ID
+		}
+	}
+
+	for _, f := range files {
+		if f.MessageID != "" || links[f.Filename] != "" {
+			continue
+		}
+		// Clip times come from SQLite with second resolution.
+		saved := f.CreatedAt.Add(time.Second)
+
+		lastHost := -1
+		for i, msg := range messages {
+			if msg.Timestamp.After(saved) {
+				break
+			}
+			if msg.Speaker == models.HOST {
+				lastHost = i
+			}
+		}
 
-	if err := os.WriteFile(filepath, audioData, 0644); err != nil {
-		return "", fmt.Errorf("failed to write audio file: %w", err)
+		if f.Speaker == models.HOST {
+			if lastHost >= 0 {
+				links[f.Filename] = messages[lastHost].ID
+			}
+			continue
+		}
+		for _, msg := range messages[lastHost+1:] {
+			if msg.Speaker == models.GUEST {
+				links[f.Filename] = msg.ID
+				break
+			}
+		}
 	}
 
-	return filename, nil
+	for _, f := range files {
+		if f.MessageID != "" {
+			delete(links, f.Filename)
+		}
+	}
+	return links
+}
+
+// CanonicalAudio drops the clips that voice alternate takes, keeping the
+// order of files.
+func CanonicalAudio(messages []Message, files []models.AudioFile) []models.AudioFile {
+	alternates := map[string]bool{}
+	for _, msg := range messages {
+		if msg.Alternate {
+			alternates[msg.ID] = true
+		}
+	}
+
+	canonical := make([]models.AudioFile, 0, len(files))
+	for _, f := range files {
+		if !alternates[f.MessageID] {
+			canonical = append(canonical, f)
+		}
+	}
+	return canonical
+}
+
+func newAudioFile(conversationID string, index int, filename string, data []byte) models.AudioFile {
+	file := models.AudioFile{
+		ConversationID: conversationID,
+		Index:          index,
+		Filename:       filename,
+		Speaker:        models.GUEST,
+		Format:         strings.TrimPrefix(filepath.Ext(filename), "."),
+		SizeBytes:      int64(len(data)),
+	}
+	if info, err := audio.Probe(data); err == nil {
+		file.Duration = info.Duration
+		file.SampleRate = info.SampleRate
+		file.Channels = info.Channels
+	}
+	return file
 }
 
 func ReadAudio(conversationID string, filename string) ([]byte, error) {
@@ -106,7 +295,7 @@ func ReadAudio(conversationID string, filename string) ([]byte, error) {
 
 	filepath := filepath.Join(audioDir, filename)
 
-	data, err := os.ReadFile(filepath)
+	data, err := crypt.ReadFile(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read audio file: %w", err)
 	}
@@ -114,6 +303,29 @@ func ReadAudio(conversationID string, filename string) ([]byte, error) {
 	return data, nil
 }
 
+// CopyAudioFile copies a clip into another conversation's audio directory
+// under the same name.
+func CopyAudioFile(fromConversationID, toConversationID, filename string) error {
+	data, err := ReadAudio(fromConversationID, filename)
+	if err != nil {
+		return err
+	}
+
+	audioDir, err := GetAudioDir(toConversationID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(audioDir, 0755); err != nil {
+		return fmt.Errorf("failed to create audio directory: %w", err)
+	}
+
+	if err := crypt.WriteFile(filepath.Join(audioDir, filename), data, 0644); err != nil {
+		return fmt.Errorf("failed to write audio file: %w", err)
+	}
+
+	return nil
+}
+
 func DeleteAudioFile(conversationID string, filename string) error {
 	audioDir, err := GetAudioDir(conversationID)
 	if err != nil {
@@ -166,3 +378,38 @@ func ListAudioFiles(conversationID string) ([]string, error) {
 
 	return files, nil
 }
+
+// ResealAudio rewrites a conversation's clips encrypted with the loaded key
+// when seal is set, otherwise in the clear, and returns how many changed.
+func ResealAudio(conversationID string, seal bool) (int, error) {
+	audioDir, err := GetAudioDir(conversationID)
+	if err != nil {
+		return 0, err
+	}
+	entries, err := os.ReadDir(audioDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read audio directory: %w", err)
+	}
+
+	mu := getAudioMutex(conversationID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	changed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !IsClipName(entry.Name()) {
+			continue
+		}
+		ok, err := crypt.ResealFile(filepath.Join(audioDir, entry.Name()), seal)
+		if err != nil {
+			return changed, err
+		}
+		if ok {
+			changed++
+		}
+	}
+	return changed, nil
+}
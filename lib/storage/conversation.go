@@ -4,20 +4,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/nraghuveer/vibecast/lib/config"
 )
 
 const (
 	conversationsDirName = "conversations"
 	audioDirName         = "audio"
+
+	// trashPrefix marks a conversation folder moved aside while it is
+	// being deleted.
+	trashPrefix = ".trash-"
 )
 
+// GetVibecastDir returns the data root; see config.GetDataDir.
 func GetVibecastDir() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+	dir := config.GetDataDir()
+	if dir == "" {
+		return "", fmt.Errorf("failed to resolve data directory")
 	}
 
-	return filepath.Join(homeDir, ".vibecast"), nil
+	return dir, nil
 }
 
 func GetConversationsDir() (string, error) {
@@ -87,6 +95,64 @@ func DeleteConversationDir(id string) error {
 	return nil
 }
 
+// PendingDelete is a conversation folder moved aside until the rest of the
+// conversation is deleted, so the deletion can still be undone.
+type PendingDelete struct {
+	dir   string
+	trash string // "" when there was no folder to move
+}
+
+// StageConversationDirDelete moves a conversation's folder out of the way.
+// Call Commit once the database row is gone, or Restore to put it back.
+func StageConversationDirDelete(id string) (*PendingDelete, error) {
+	conversationsDir, err := GetConversationsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := &PendingDelete{dir: filepath.Join(conversationsDir, id)}
+	if _, err := os.Stat(pending.dir); os.IsNotExist(err) {
+		return pending, nil
+	}
+
+	pending.trash = filepath.Join(conversationsDir, trashPrefix+id)
+	if err := os.RemoveAll(pending.trash); err != nil {
+		return nil, fmt.Errorf("failed to clear stale deleted folder: %w", err)
+	}
+	if err := os.Rename(pending.dir, pending.trash); err != nil {
+		return nil, fmt.Errorf("failed to move conversation directory: %w", err)
+	}
+	return pending, nil
+}
+
+// Restore moves the folder back to where it was.
+func (p *PendingDelete) Restore() error {
+	if p.trash == "" {
+		return nil
+	}
+	if err := os.Rename(p.trash, p.dir); err != nil {
+		return fmt.Errorf("failed to restore conversation directory: %w", err)
+	}
+	return nil
+}
+
+// Commit removes the folder for good.
+func (p *PendingDelete) Commit() error {
+	if p.trash == "" {
+		return nil
+	}
+	if err := os.RemoveAll(p.trash); err != nil {
+		return fmt.Errorf("failed to delete conversation directory: %w", err)
+	}
+	return nil
+}
+
+// IsTrashDir reports whether a folder under conversations/ is a deletion
+// that didn't finish.
+func IsTrashDir(name string) bool {
+	return strings.HasPrefix(name, trashPrefix)
+}
+
 func DeleteAllConversationsDirs() error {
 	conversationsDir, err := GetConversationsDir()
 	if err != nil {
@@ -1,24 +1,50 @@
 package storage
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/nraghuveer/vibecast/lib/atomicfile"
+	"github.com/nraghuveer/vibecast/lib/crypt"
 	"github.com/nraghuveer/vibecast/lib/models"
 )
 
 const (
-	transcriptFileName = "transcript.txt"
+	transcriptFileName = "transcript.jsonl"
+
+	// legacyTranscriptFileName is the original "[ts] Speaker: content"
+	// format. It is converted on first use and renamed with
+	// migratedSuffix so the conversion only happens once.
+	legacyTranscriptFileName = "transcript.txt"
+	migratedSuffix           = ".migrated"
+
+	// TranscriptVersion is written into every transcript record.
+	TranscriptVersion = 1
 )
 
 var (
 	transcriptMutexes sync.Map
 )
 
+// MessageIndex keeps a searchable copy of transcript messages. *db.DB
+// implements it; the transcript file stays the source of truth.
+type MessageIndex interface {
+	RecordMessage(m models.Message) error
+	SetMessageAlternate(messageID string, alternate bool) error
+	CountMessages(conversationID string) (int, error)
+	DeleteMessage(messageID string) error
+}
+
 func getTranscriptMutex(id string) *sync.Mutex {
 	mu, _ := transcriptMutexes.LoadOrStore(id, &sync.Mutex{})
 	return mu.(*sync.Mutex)
@@ -33,6 +59,108 @@ func getTranscriptPath(conversationID string) (string, error) {
 	return filepath.Join(conversationDir, transcriptFileName), nil
 }
 
+// TranscriptPath returns where a conversation's transcript.jsonl lives.
+func TranscriptPath(conversationID string) (string, error) {
+	return getTranscriptPath(conversationID)
+}
+
+func getLegacyTranscriptPath(conversationID string) (string, error) {
+	conversationDir, err := GetConversationDir(conversationID)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(conversationDir, legacyTranscriptFileName), nil
+}
+
+// Message is one transcript record: a single turn by the host or the guest.
+type Message struct {
+	Version   int                `json:"v"`
+	ID        string             `json:"id"`
+	Timestamp time.Time          `json:"ts"` // when the turn was recorded
+	Speaker   models.SpeakerType `json:"speaker"`
+	Content   string             `json:"content"`
+	Audio     []string           `json:"audio,omitempty"` // clip file names in the audio folder
+
+	// Timings of a generated answer.
+	StartedAt    *time.Time `json:"started_at,omitempty"`     // when the request was sent
+	FirstTokenMs int64      `json:"first_token_ms,omitempty"` // until the first streamed text
+	DurationMs   int64      `json:"duration_ms,omitempty"`    // until the answer completed
+
+	Provider    string `json:"provider,omitempty"`
+	Model       string `json:"model,omitempty"`
+	Truncated   bool   `json:"truncated,omitempty"`   // the provider stopped at its length limit
+	Interrupted bool   `json:"interrupted,omitempty"` // the stream failed part way through
+
+	// Retakes of a guest answer. Every take after the first names the first
+	// in TakeOf; all but the canonical take are marked Alternate.
+	TakeOf    string `json:"take_of,omitempty"`
+	Alternate bool   `json:"alternate,omitempty"`
+}
+
+// TakeGroup returns the id shared by all takes of msg's answer: the first
+// take's id.
+func (msg Message) TakeGroup() string {
+	if msg.TakeOf != "" {
+		return msg.TakeOf
+	}
+	return msg.ID
+}
+
+// Canonical drops alternate takes, leaving the conversation as it is played,
+// exported and sent to the guest.
+func Canonical(messages []Message) []Message {
+	canonical := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		if !msg.Alternate {
+			canonical = append(canonical, msg)
+		}
+	}
+	return canonical
+}
+
+// SetCanonicalTake makes messageID the canonical take of its answer and
+// marks the answer's other takes as alternates, in the transcript and the
+// index.
+func SetCanonicalTake(index MessageIndex, conversationID, messageID string) error {
+	messages, err := LoadMessages(conversationID)
+	if err != nil {
+		return err
+	}
+
+	group := ""
+	for _, msg := range messages {
+		if msg.ID == messageID {
+			group = msg.TakeGroup()
+			break
+		}
+	}
+	if group == "" {
+		return fmt.Errorf("message not found")
+	}
+
+	for i, msg := range messages {
+		if msg.TakeGroup() == group {
+			messages[i].Alternate = msg.ID != messageID
+		}
+	}
+	if err := WriteTranscript(conversationID, messages); err != nil {
+		return err
+	}
+
+	if index != nil {
+		for _, msg := range messages {
+			if msg.TakeGroup() != group {
+				continue
+			}
+			if err := index.SetMessageAlternate(msg.ID, msg.Alternate); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func CreateTranscript(conversationID string) error {
 	transcriptPath, err := getTranscriptPath(conversationID)
 	if err != nil {
@@ -46,180 +174,642 @@ func CreateTranscript(conversationID string) error {
 	return nil
 }
 
-func AppendMessage(conversationID string, speaker string, content string) error {
+// AppendRecord appends msg to the transcript, filling in its version, ID and
+// timestamp when they are unset, records it in the index, and returns the
+// stored record.
+func AppendRecord(index MessageIndex, conversationID string, msg Message) (Message, error) {
 	mu := getTranscriptMutex(conversationID)
 	mu.Lock()
 	defer mu.Unlock()
 
+	if err := migrateTranscript(conversationID); err != nil {
+		return msg, err
+	}
+
 	transcriptPath, err := getTranscriptPath(conversationID)
 	if err != nil {
-		return err
+		return msg, err
 	}
 
-	timestamp := time.Now().Format(time.RFC3339)
-	message := fmt.Sprintf("[%s] %s: %s\n", timestamp, speaker, content)
+	msg.Version = TranscriptVersion
+	if msg.ID == "" {
+		msg.ID = uuid.New().String()
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+
+	line, err := encodeRecord(msg)
+	if err != nil {
+		return msg, err
+	}
 
-	file, err := os.OpenFile(transcriptPath, os.O_APPEND|os.O_WRONLY, 0644)
+	file, err := os.OpenFile(transcriptPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to open transcript file: %w", err)
+		return msg, fmt.Errorf("failed to open transcript file: %w", err)
 	}
 	defer file.Close()
 
-	if _, err := file.WriteString(message); err != nil {
-		return fmt.Errorf("failed to write message to transcript: %w", err)
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return msg, fmt.Errorf("failed to write message to transcript: %w", err)
 	}
 
-	return nil
+	if index != nil {
+		if err := index.RecordMessage(msg.indexed(conversationID)); err != nil {
+			return msg, err
+		}
+	}
+
+	return msg, nil
 }
 
-func AppendMessageWithAudio(conversationID string, speaker string, content string, audioFile string) error {
+func (msg Message) indexed(conversationID string) models.Message {
+	return models.Message{
+		ID:             msg.ID,
+		ConversationID: conversationID,
+		Speaker:        msg.Speaker,
+		Content:        msg.Content,
+		CreatedAt:      msg.Timestamp,
+		TakeOf:         msg.TakeOf,
+		Alternate:      msg.Alternate,
+	}
+}
+
+// AddMessageAudio adds a clip to the audio of a message already in the
+// transcript, for clips saved after their message was written. Only that
+// record is rewritten; other lines, readable or not, are kept as they are.
+// It reports whether the message was found.
+func AddMessageAudio(conversationID, messageID, filename string) (bool, error) {
 	mu := getTranscriptMutex(conversationID)
 	mu.Lock()
 	defer mu.Unlock()
 
 	transcriptPath, err := getTranscriptPath(conversationID)
 	if err != nil {
-		return err
+		return false, err
 	}
+	data, err := os.ReadFile(transcriptPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read transcript file: %w", err)
+	}
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i, line := range lines {
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 {
+			continue
+		}
+		msg, err := decodeRecord(trimmed)
+		if err != nil || msg.ID != messageID {
+			continue
+		}
+		if slices.Contains(msg.Audio, filename) {
+			return true, nil
+		}
+		msg.Audio = append(msg.Audio, filename)
+		encoded, err := encodeRecord(msg)
+		if err != nil {
+			return true, err
+		}
+		lines[i] = append(encoded, '\n')
 
-	timestamp := time.Now().Format(time.RFC3339)
-	message := fmt.Sprintf("[%s] %s: %s [Audio: %s]\n", timestamp, speaker, content, audioFile)
+		if err := atomicfile.Write(transcriptPath, bytes.Join(lines, nil), 0644); err != nil {
+			return true, fmt.Errorf("failed to write transcript file: %w", err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
 
-	file, err := os.OpenFile(transcriptPath, os.O_APPEND|os.O_WRONLY, 0644)
+// ReadTranscript returns the transcript as readable text, one
+// "[ts] Speaker: content" entry per message, leaving out alternate takes.
+func ReadTranscript(conversationID string) (string, error) {
+	messages, err := LoadMessages(conversationID)
 	if err != nil {
-		return fmt.Errorf("failed to open transcript file: %w", err)
+		return "", err
 	}
-	defer file.Close()
 
-	if _, err := file.WriteString(message); err != nil {
-		return fmt.Errorf("failed to write message to transcript: %w", err)
+	var b strings.Builder
+	for _, m := range Canonical(messages) {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", m.Timestamp.Format(time.RFC3339), m.Speaker, m.Content)
+	}
+	return b.String(), nil
+}
+
+func TranscriptExists(conversationID string) (bool, error) {
+	for _, pathFor := range []func(string) (string, error){getTranscriptPath, getLegacyTranscriptPath} {
+		transcriptPath, err := pathFor(conversationID)
+		if err != nil {
+			return false, err
+		}
+
+		_, err = os.Stat(transcriptPath)
+		if err == nil {
+			return true, nil
+		}
+		if !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func DeleteTranscript(conversationID string) error {
+	for _, pathFor := range []func(string) (string, error){getTranscriptPath, getLegacyTranscriptPath} {
+		transcriptPath, err := pathFor(conversationID)
+		if err != nil {
+			return err
+		}
+
+		if err := os.Remove(transcriptPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete transcript file: %w", err)
+		}
 	}
 
 	return nil
 }
 
-func ReadTranscript(conversationID string) (string, error) {
+// LoadMessages loads all messages from the transcript file, converting a
+// legacy transcript.txt first. If the conversion can't be written, e.g. on
+// a read-only data directory, the transcript it would have written is read
+// instead, with the same message ids. Neither that nor skipped lines are
+// logged here; CheckTranscript finds both so doctor can report them.
+func LoadMessages(conversationID string) ([]Message, error) {
+	mu := getTranscriptMutex(conversationID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := migrateTranscript(conversationID); err != nil {
+		converted, found, convertErr := convertLegacyTranscript(conversationID)
+		if convertErr != nil {
+			return nil, convertErr
+		}
+		if found {
+			return decodeTranscript(converted)
+		}
+	}
+
 	transcriptPath, err := getTranscriptPath(conversationID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	data, err := os.ReadFile(transcriptPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read transcript file: %w", err)
+		if os.IsNotExist(err) {
+			return []Message{}, nil
+		}
+		return nil, fmt.Errorf("failed to read transcript file: %w", err)
 	}
 
-	return string(data), nil
+	return decodeTranscript(data)
 }
 
-func TranscriptExists(conversationID string) (bool, error) {
+// WriteTranscript replaces a conversation's transcript with messages. The
+// file is written to a temp file and renamed, so a crash leaves either the
+// old transcript or the new one. The search index is not touched.
+func WriteTranscript(conversationID string, messages []Message) error {
+	mu := getTranscriptMutex(conversationID)
+	mu.Lock()
+	defer mu.Unlock()
+
 	transcriptPath, err := getTranscriptPath(conversationID)
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	_, err = os.Stat(transcriptPath)
-	if err == nil {
-		return true, nil
+	var buf bytes.Buffer
+	for _, msg := range messages {
+		msg.Version = TranscriptVersion
+		line, err := encodeRecord(msg)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
 	}
-	if os.IsNotExist(err) {
-		return false, nil
+
+	if err := atomicfile.Write(transcriptPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write transcript file: %w", err)
 	}
-	return false, err
+	return nil
 }
 
-func DeleteTranscript(conversationID string) error {
-	transcriptPath, err := getTranscriptPath(conversationID)
+// TruncateTranscript removes messageID and every record after it from the
+// transcript and the index, and returns the removed records. The
+// transcript is rewritten in one step; index rows are removed afterwards.
+func TruncateTranscript(index MessageIndex, conversationID, messageID string) ([]Message, error) {
+	messages, err := LoadMessages(conversationID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := os.Remove(transcriptPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete transcript file: %w", err)
+	cut := -1
+	for i, msg := range messages {
+		if msg.ID == messageID {
+			cut = i
+			break
+		}
+	}
+	if cut < 0 {
+		return nil, fmt.Errorf("message not found")
 	}
 
-	return nil
+	if err := WriteTranscript(conversationID, messages[:cut]); err != nil {
+		return nil, err
+	}
+
+	removed := messages[cut:]
+	for _, msg := range removed {
+		if err := index.DeleteMessage(msg.ID); err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
 }
 
-// Message represents a parsed message from the transcript
-type Message struct {
-	Timestamp time.Time
-	Speaker   models.SpeakerType
-	Content   string
+// TranscriptCheck describes a conversation's transcript on disk.
+type TranscriptCheck struct {
+	Exists     bool // transcript.jsonl, or a legacy transcript.txt
+	Legacy     bool // a transcript.txt that hasn't been converted
+	Records    int  // messages that decode, counting unconverted ones
+	Unreadable int  // lines that don't
 }
 
-// LoadMessages loads all messages from the transcript file
-func LoadMessages(conversationID string) ([]Message, error) {
-	transcriptPath, err := getTranscriptPath(conversationID)
+// CheckTranscript reads a transcript without converting or changing it.
+func CheckTranscript(conversationID string) (TranscriptCheck, error) {
+	mu := getTranscriptMutex(conversationID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	var check TranscriptCheck
+	data, legacy, err := convertLegacyTranscript(conversationID)
 	if err != nil {
-		return nil, err
+		return check, err
+	}
+	if !legacy {
+		transcriptPath, err := getTranscriptPath(conversationID)
+		if err != nil {
+			return check, err
+		}
+		data, err = os.ReadFile(transcriptPath)
+		if os.IsNotExist(err) {
+			return check, nil
+		}
+		if err != nil {
+			return check, fmt.Errorf("failed to read transcript file: %w", err)
+		}
+	}
+	check.Legacy = legacy
+
+	check.Exists = true
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if _, err := decodeRecord(line); err == nil {
+			check.Records++
+		} else if errors.Is(err, crypt.ErrLocked) {
+			return check, err
+		} else {
+			check.Unreadable++
+		}
+	}
+	return check, nil
+}
+
+// IndexMessages records a conversation's transcript in the index when the
+// index is missing any of it, and returns how many messages were recorded.
+func IndexMessages(index MessageIndex, conversationID string) (int, error) {
+	messages, err := LoadMessages(conversationID)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := index.CountMessages(conversationID)
+	if err != nil {
+		return 0, err
+	}
+	if count >= len(messages) {
+		return 0, nil
+	}
+
+	for _, msg := range messages {
+		if err := index.RecordMessage(msg.indexed(conversationID)); err != nil {
+			return 0, err
+		}
+	}
+	return len(messages) - count, nil
+}
+
+// DecodeTranscript parses JSONL records. Lines that don't decode, such as a
+// write cut short by a crash, are skipped.
+func DecodeTranscript(data []byte) []Message {
+	messages, _ := decodeTranscript(data)
+	return messages
+}
+
+// decodeTranscript is DecodeTranscript, but fails with crypt.ErrLocked on a
+// sealed line when no key is loaded rather than skip it, so a locked
+// transcript is never mistaken for an empty one and rewritten.
+func decodeTranscript(data []byte) ([]Message, error) {
+	messages := []Message{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		msg, err := decodeRecord(line)
+		if errors.Is(err, crypt.ErrLocked) {
+			return messages, err
+		}
+		if err != nil {
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+// encodeRecord encodes one transcript line, sealed when encryption is on.
+func encodeRecord(msg Message) ([]byte, error) {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transcript message: %w", err)
+	}
+	return []byte(crypt.SealString(string(line))), nil
+}
+
+// decodeRecord decodes one transcript line, opening it if it is sealed.
+func decodeRecord(line []byte) (Message, error) {
+	var msg Message
+	plain, err := crypt.OpenString(string(line))
+	if err != nil {
+		return msg, err
+	}
+	if err := json.Unmarshal([]byte(plain), &msg); err != nil {
+		return msg, err
 	}
+	return msg, nil
+}
 
+// ResealTranscript rewrites a conversation's transcript encrypted with the
+// loaded key when seal is set, otherwise in the clear, line by line so
+// damaged records stay as they are for doctor. A retired transcript.txt is
+// resealed too. It reports whether anything changed.
+func ResealTranscript(conversationID string, seal bool) (bool, error) {
+	mu := getTranscriptMutex(conversationID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	legacyPath, err := getLegacyTranscriptPath(conversationID)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	if _, err := os.Stat(legacyPath + migratedSuffix); err == nil {
+		if changed, err = crypt.ResealFile(legacyPath+migratedSuffix, seal); err != nil {
+			return false, err
+		}
+	}
+
+	transcriptPath, err := getTranscriptPath(conversationID)
+	if err != nil {
+		return changed, err
+	}
 	data, err := os.ReadFile(transcriptPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return []Message{}, nil
+			return changed, nil
 		}
-		return nil, fmt.Errorf("failed to read transcript file: %w", err)
+		return changed, fmt.Errorf("failed to read transcript file: %w", err)
+	}
+
+	var buf bytes.Buffer
+	rewritten := false
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		text := strings.TrimRight(line, "\n")
+		if strings.TrimSpace(text) == "" || crypt.IsSealedString(text) == seal {
+			buf.WriteString(line)
+			continue
+		}
+		if seal {
+			if !crypt.Enabled() {
+				return changed, crypt.ErrLocked
+			}
+			text = crypt.SealString(text)
+		} else if text, err = crypt.OpenString(text); err != nil {
+			return changed, fmt.Errorf("failed to decrypt transcript: %w", err)
+		}
+		buf.WriteString(text + line[len(strings.TrimRight(line, "\n")):])
+		rewritten = true
+	}
+	if !rewritten {
+		return changed, nil
 	}
 
-	return parseTranscript(string(data)), nil
+	if err := atomicfile.Write(transcriptPath, buf.Bytes(), 0644); err != nil {
+		return changed, fmt.Errorf("failed to write transcript file: %w", err)
+	}
+	return true, nil
 }
 
-// parseTranscript parses transcript content into messages
-func parseTranscript(content string) []Message {
-	var messages []Message
-	lines := strings.Split(content, "\n")
+// MigrateTranscripts converts every conversation's legacy transcript.txt
+// and returns how many were converted.
+func MigrateTranscripts() (int, error) {
+	conversationsDir, err := GetConversationsDir()
+	if err != nil {
+		return 0, err
+	}
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
+	entries, err := os.ReadDir(conversationsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
 		}
+		return 0, fmt.Errorf("failed to read conversations directory: %w", err)
+	}
 
-		// Parse format: [2023-10-01T12:34:56Z] Speaker: Content
-		if !strings.HasPrefix(line, "[") {
+	migrated := 0
+	for _, entry := range entries {
+		if !entry.IsDir() || IsTrashDir(entry.Name()) {
 			continue
 		}
-
-		// Find closing bracket for timestamp
-		closeBracket := strings.Index(line, "]")
-		if closeBracket == -1 {
+		legacyPath := filepath.Join(conversationsDir, entry.Name(), legacyTranscriptFileName)
+		if _, err := os.Stat(legacyPath); err != nil {
 			continue
 		}
 
-		timestampStr := line[1:closeBracket]
-		timestamp, err := time.Parse(time.RFC3339, timestampStr)
-		if err != nil {
-			continue
+		if err := MigrateTranscript(entry.Name()); err != nil {
+			return migrated, err
 		}
+		migrated++
+	}
+	return migrated, nil
+}
 
-		// Get the rest after the bracket and space
-		rest := strings.TrimSpace(line[closeBracket+1:])
-		if rest == "" {
-			continue
+// MigrateTranscript converts a conversation's legacy transcript.txt, if it
+// has one.
+func MigrateTranscript(conversationID string) error {
+	mu := getTranscriptMutex(conversationID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	return migrateTranscript(conversationID)
+}
+
+// migrateTranscript converts a legacy transcript.txt to JSONL and retires
+// it. The caller holds the transcript mutex.
+func migrateTranscript(conversationID string) error {
+	converted, found, err := convertLegacyTranscript(conversationID)
+	if err != nil || !found {
+		return err
+	}
+
+	transcriptPath, err := getTranscriptPath(conversationID)
+	if err != nil {
+		return err
+	}
+	if err := atomicfile.Write(transcriptPath, converted, 0644); err != nil {
+		return fmt.Errorf("failed to write migrated transcript: %w", err)
+	}
+	legacyPath, err := getLegacyTranscriptPath(conversationID)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(legacyPath, legacyPath+migratedSuffix); err != nil {
+		return fmt.Errorf("failed to retire legacy transcript: %w", err)
+	}
+	return nil
+}
+
+// convertLegacyTranscript returns the transcript.jsonl that converting a
+// legacy transcript.txt produces, and whether there is one to convert.
+// Records already in transcript.jsonl, e.g. appended by a newer build
+// before the conversion ran, are kept after the converted ones. Converted
+// records get ids derived from their position, so a conversion that wrote
+// transcript.jsonl but couldn't retire transcript.txt finds its records
+// already there and doesn't add them again.
+func convertLegacyTranscript(conversationID string) ([]byte, bool, error) {
+	legacyPath, err := getLegacyTranscriptPath(conversationID)
+	if err != nil {
+		return nil, false, err
+	}
+	legacy, err := os.ReadFile(legacyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
 		}
+		return nil, false, fmt.Errorf("failed to read legacy transcript: %w", err)
+	}
 
-		// Find speaker and content
-		colonIdx := strings.Index(rest, ":")
-		if colonIdx == -1 {
+	transcriptPath, err := getTranscriptPath(conversationID)
+	if err != nil {
+		return nil, false, err
+	}
+	existing, err := os.ReadFile(transcriptPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, false, fmt.Errorf("failed to read transcript file: %w", err)
+	}
+	present, err := decodeTranscript(existing)
+	if err != nil {
+		return nil, false, err
+	}
+	ids := map[string]bool{}
+	for _, msg := range present {
+		ids[msg.ID] = true
+	}
+
+	var buf bytes.Buffer
+	for i, msg := range parseTranscript(string(legacy)) {
+		msg.ID = legacyMessageID(conversationID, i)
+		if ids[msg.ID] {
 			continue
 		}
+		line, err := encodeRecord(msg)
+		if err != nil {
+			return nil, false, err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.Write(existing)
+	return buf.Bytes(), true, nil
+}
 
-		speakerStr := strings.TrimSpace(rest[:colonIdx])
-		content := strings.TrimSpace(rest[colonIdx+1:])
+// legacyMessageID is the id given to the i-th message of a conversation's
+// legacy transcript.
+func legacyMessageID(conversationID string, i int) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, fmt.Appendf(nil, "vibecast:transcript.txt/%s/%d", conversationID, i)).String()
+}
 
-		// Skip audio references in content
-		if idx := strings.Index(content, " [Audio:"); idx != -1 {
-			content = strings.TrimSpace(content[:idx])
+// parseTranscript parses the legacy transcript format into messages.
+// A line that doesn't start a new "[ts] Speaker: content" entry continues
+// the previous message's content.
+func parseTranscript(content string) []Message {
+	messages := []Message{}
+	for _, line := range strings.Split(content, "\n") {
+		msg, ok := parseTranscriptLine(line)
+		if !ok {
+			if n := len(messages); n > 0 {
+				messages[n-1].Content += "\n" + strings.TrimRight(line, "\r")
+			}
+			continue
 		}
+		messages = append(messages, msg)
+	}
 
-		messages = append(messages, Message{
-			Timestamp: timestamp,
-			Speaker:   models.ParseSpeakerType(speakerStr),
-			Content:   content,
-		})
+	for i := range messages {
+		messages[i].Content = strings.TrimSpace(messages[i].Content)
+		// The audio reference was written after the content, so for
+		// multi-line messages it is on the last line.
+		if idx := strings.LastIndex(messages[i].Content, " [Audio: "); idx != -1 && strings.HasSuffix(messages[i].Content, "]") {
+			ref := messages[i].Content[idx+len(" [Audio: ") : len(messages[i].Content)-1]
+			messages[i].Audio = []string{strings.TrimSpace(ref)}
+			messages[i].Content = strings.TrimSpace(messages[i].Content[:idx])
+		}
 	}
 
 	return messages
 }
+
+// parseTranscriptLine parses "[2023-10-01T12:34:56Z] Speaker: Content".
+func parseTranscriptLine(line string) (Message, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "[") {
+		return Message{}, false
+	}
+
+	closeBracket := strings.Index(line, "]")
+	if closeBracket == -1 {
+		return Message{}, false
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, line[1:closeBracket])
+	if err != nil {
+		return Message{}, false
+	}
+
+	rest := strings.TrimSpace(line[closeBracket+1:])
+	colonIdx := strings.Index(rest, ":")
+	if colonIdx == -1 {
+		return Message{}, false
+	}
+
+	speaker := strings.TrimSpace(rest[:colonIdx])
+	if speaker != models.HOST.String() && speaker != models.GUEST.String() {
+		return Message{}, false
+	}
+
+	return Message{
+		Version:   TranscriptVersion,
+		Timestamp: timestamp,
+		Speaker:   models.ParseSpeakerType(speaker),
+		Content:   strings.TrimSpace(rest[colonIdx+1:]),
+	}, true
+}
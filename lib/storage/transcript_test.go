@@ -0,0 +1,226 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"os"
+	"testing"
+
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+	"github.com/nraghuveer/vibecast/lib/models"
+)
+
+// useDataDir points the data directory at a fresh temp directory.
+func useDataDir(t *testing.T) {
+	t.Helper()
+	t.Setenv(config.HomeEnvVar, t.TempDir())
+}
+
+// useKey loads a random encryption key for the test.
+func useKey(t *testing.T) {
+	t.Helper()
+	raw := make([]byte, crypt.KeySize)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatal(err)
+	}
+	k, err := crypt.NewKey(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crypt.SetKey(k)
+	t.Cleanup(func() { crypt.SetKey(nil) })
+}
+
+func newTranscript(t *testing.T, id string, contents ...string) {
+	t.Helper()
+	if _, err := CreateConversationDir(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateTranscript(id); err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range contents {
+		if _, err := AppendRecord(nil, id, Message{Speaker: models.HOST, Content: content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResealTranscriptIsIdempotent(t *testing.T) {
+	useDataDir(t)
+	const id = "conv"
+	newTranscript(t, id, "first question", "second question")
+	path, err := TranscriptPath(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	useKey(t)
+	for _, step := range []struct {
+		seal    bool
+		changed bool
+	}{
+		{true, true},
+		{true, false},
+		{false, true},
+		{false, false},
+	} {
+		changed, err := ResealTranscript(id, step.seal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != step.changed {
+			t.Errorf("ResealTranscript(seal=%v) changed=%v, want %v", step.seal, changed, step.changed)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sealed := bytes.Contains(data, []byte("question")); sealed == step.seal {
+			t.Errorf("after ResealTranscript(seal=%v) the transcript reads %q", step.seal, data)
+		}
+		messages, err := LoadMessages(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(messages) != 2 || messages[1].Content != "second question" {
+			t.Errorf("after ResealTranscript(seal=%v) loaded %+v", step.seal, messages)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, plain) {
+		t.Errorf("after sealing and opening the transcript is\n%s\nwant\n%s", data, plain)
+	}
+}
+
+func TestResealTranscriptFinishesPartialRun(t *testing.T) {
+	useDataDir(t)
+	const id = "conv"
+	newTranscript(t, id, "written before encryption")
+
+	// A record appended once the key was loaded is sealed already, as
+	// after an interrupted encrypt.
+	useKey(t)
+	if _, err := AppendRecord(nil, id, Message{Speaker: models.GUEST, Content: "written after"}); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := ResealTranscript(id, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Error("ResealTranscript left the plain record as it was")
+	}
+	path, err := TranscriptPath(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("written")) {
+		t.Errorf("transcript still has readable records: %q", data)
+	}
+	messages, err := LoadMessages(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 {
+		t.Errorf("loaded %d messages, want 2", len(messages))
+	}
+}
+
+func TestLoadMessagesLockedTranscript(t *testing.T) {
+	useDataDir(t)
+	const id = "conv"
+	useKey(t)
+	newTranscript(t, id, "sealed")
+	crypt.SetKey(nil)
+
+	if _, err := LoadMessages(id); err == nil {
+		t.Error("LoadMessages read a sealed transcript without a key")
+	}
+}
+
+// writeLegacyTranscript gives a conversation a transcript.txt holding two
+// messages and a transcript.jsonl holding one written after them.
+func writeLegacyTranscript(t *testing.T, id string) {
+	t.Helper()
+	newTranscript(t, id, "appended by a newer build")
+	legacyPath, err := getLegacyTranscriptPath(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := "[2024-01-02T03:04:05Z] Host: first question\n[2024-01-02T03:04:09Z] Guest: first answer\n"
+	if err := os.WriteFile(legacyPath, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateTranscriptRunsOnce(t *testing.T) {
+	useDataDir(t)
+	const id = "conv"
+	writeLegacyTranscript(t, id)
+
+	check, err := CheckTranscript(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !check.Legacy || check.Records != 3 {
+		t.Errorf("CheckTranscript before converting returned %+v", check)
+	}
+
+	if err := MigrateTranscript(id); err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := LoadMessages(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"first question", "first answer", "appended by a newer build"}
+	if len(migrated) != len(want) {
+		t.Fatalf("loaded %d messages after converting, want %d", len(migrated), len(want))
+	}
+	for i, msg := range migrated {
+		if msg.Content != want[i] {
+			t.Errorf("message %d is %q, want %q", i, msg.Content, want[i])
+		}
+	}
+
+	// A conversion that wrote transcript.jsonl but couldn't retire
+	// transcript.txt is finished by the next one without duplicates.
+	legacyPath, err := getLegacyTranscriptPath(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(legacyPath+migratedSuffix, legacyPath); err != nil {
+		t.Fatal(err)
+	}
+	again, err := LoadMessages(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != len(migrated) {
+		t.Fatalf("loaded %d messages after converting again, want %d", len(again), len(migrated))
+	}
+	for i := range again {
+		if again[i].ID != migrated[i].ID {
+			t.Errorf("message %d changed id from %s to %s", i, migrated[i].ID, again[i].ID)
+		}
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Error("transcript.txt wasn't retired")
+	}
+}
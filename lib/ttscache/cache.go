@@ -0,0 +1,314 @@
+package ttscache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/atomicfile"
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/crypt"
+	"github.com/nraghuveer/vibecast/lib/models"
+	"github.com/nraghuveer/vibecast/lib/storage"
+)
+
+// keyVersion is mixed into every hash; bump it to invalidate old entries
+// when the meaning of a key changes.
+const keyVersion = "v1"
+
+const metaExt = ".json"
+
+// Key identifies a synthesized clip. Two requests with equal keys produce
+// interchangeable audio.
+type Key struct {
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model"`
+	Voice        string  `json:"voice"`
+	Speed        float64 `json:"speed,omitempty"`
+	Instructions string  `json:"instructions,omitempty"`
+	Format       string  `json:"format"`
+	Text         string  `json:"text"`
+}
+
+// NewKey builds the key for synthesizing text with a voice on a provider.
+// Whitespace in text is collapsed so formatting differences still hit.
+func NewKey(provider string, voice models.VoiceProfile, text string) Key {
+	model, _ := config.GetProviderTTSModel(provider)
+	return Key{
+		Provider:     provider,
+		Model:        model,
+		Voice:        voice.VoiceID,
+		Speed:        voice.Speed,
+		Instructions: strings.TrimSpace(voice.Instructions),
+		Format:       config.GetProviderTTSFormat(provider),
+		Text:         strings.Join(strings.Fields(text), " "),
+	}
+}
+
+// Hash returns the content address of the key.
+func (k Key) Hash() string {
+	data, _ := json.Marshal(k)
+	sum := sha256.Sum256(append([]byte(keyVersion+"\n"), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+// Entry describes one cached clip.
+type Entry struct {
+	Key      Key       `json:"key"`
+	Hash     string    `json:"-"`
+	File     string    `json:"-"`
+	Size     int64     `json:"-"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"-"`
+}
+
+// Stats summarizes the cache.
+type Stats struct {
+	Dir      string
+	Entries  int
+	Bytes    int64
+	MaxBytes int64
+}
+
+var mu sync.Mutex
+
+// Dir returns the cache directory, <data_dir>/cache/tts.
+func Dir() (string, error) {
+	vibeDir, err := storage.GetVibecastDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(vibeDir, "cache", "tts"), nil
+}
+
+// Get returns the cached audio for key and marks it as recently used.
+func Get(key Key) ([]byte, bool) {
+	if !config.GetCacheConfig().TTSEnabled() {
+		return nil, false
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := audioPath(key)
+	if err != nil {
+		return nil, false
+	}
+	data, err := crypt.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return nil, false
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return data, true
+}
+
+// Put stores audio for key, then evicts least recently used entries until
+// the cache fits tts_max_mb.
+func Put(key Key, data []byte) error {
+	cfg := config.GetCacheConfig()
+	if !cfg.TTSEnabled() {
+		return nil
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create tts cache directory: %w", err)
+	}
+
+	path, err := audioPath(key)
+	if err != nil {
+		return err
+	}
+	meta, err := json.Marshal(Entry{Key: key, Created: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to encode tts cache entry: %w", err)
+	}
+	// Entries hold the spoken text, so they are sealed like clips.
+	if err := writeAtomic(path, crypt.Seal(data)); err != nil {
+		return err
+	}
+	if err := writeAtomic(metaPath(path), crypt.Seal(meta)); err != nil {
+		return err
+	}
+
+	_, err = evict(dir, cfg.TTSMaxBytes())
+	return err
+}
+
+// List returns every cached entry, most recently used first.
+func List() ([]Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return list(dir)
+}
+
+// Inspect returns the cache's size and location.
+func Inspect() (Stats, error) {
+	entries, err := List()
+	if err != nil {
+		return Stats{}, err
+	}
+	dir, _ := Dir()
+	stats := Stats{Dir: dir, Entries: len(entries), MaxBytes: config.GetCacheConfig().TTSMaxBytes()}
+	for _, e := range entries {
+		stats.Bytes += e.Size
+	}
+	return stats, nil
+}
+
+// Clear removes every cached clip and returns how many were removed.
+func Clear() (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, err := Dir()
+	if err != nil {
+		return 0, err
+	}
+	entries, err := list(dir)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, fmt.Errorf("failed to clear tts cache: %w", err)
+	}
+	return len(entries), nil
+}
+
+// Reseal rewrites every cached clip and its metadata encrypted with the
+// loaded key when seal is set, otherwise in the clear, and returns how many
+// files changed.
+func Reseal(seal bool) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	dir, err := Dir()
+	if err != nil {
+		return 0, err
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read tts cache: %w", err)
+	}
+
+	changed := 0
+	for _, f := range files {
+		if f.IsDir() || strings.HasSuffix(f.Name(), ".tmp") {
+			continue
+		}
+		ok, err := crypt.ResealFile(filepath.Join(dir, f.Name()), seal)
+		if err != nil {
+			return changed, err
+		}
+		if ok {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+func audioPath(key Key) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	format := key.Format
+	if format == "" {
+		format = "bin"
+	}
+	return filepath.Join(dir, key.Hash()+"."+format), nil
+}
+
+func metaPath(audioPath string) string {
+	return strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + metaExt
+}
+
+// list reads the cache directory. An audio file's modification time is its
+// last use; entries whose metadata is missing are still listed.
+func list(dir string) ([]Entry, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read tts cache: %w", err)
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || filepath.Ext(name) == metaExt || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		entry := Entry{Hash: strings.TrimSuffix(name, filepath.Ext(name)), File: name}
+		if meta, err := crypt.ReadFile(metaPath(path)); err == nil {
+			json.Unmarshal(meta, &entry)
+		}
+		entry.Size = info.Size()
+		entry.LastUsed = info.ModTime()
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// evict removes least recently used entries until the cache is at most
+// maxBytes, returning how many were removed.
+func evict(dir string, maxBytes int64) (int, error) {
+	entries, err := list(dir)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+
+	removed := 0
+	for i := len(entries) - 1; i >= 0 && total > maxBytes; i-- {
+		e := entries[i]
+		path := filepath.Join(dir, e.File)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to evict tts cache entry: %w", err)
+		}
+		os.Remove(metaPath(path))
+		total -= e.Size
+		removed++
+	}
+	return removed, nil
+}
+
+func writeAtomic(path string, data []byte) error {
+	if err := atomicfile.Write(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write tts cache entry: %w", err)
+	}
+	return nil
+}
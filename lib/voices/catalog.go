@@ -0,0 +1,269 @@
+package voices
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nraghuveer/vibecast/lib/config"
+	"github.com/nraghuveer/vibecast/lib/db"
+	"github.com/nraghuveer/vibecast/lib/models"
+)
+
+// builtinVoices declares the voices each TTS provider ships with.
+// Providers not listed here can expose theirs through voices_url.
+var builtinVoices = map[string][]models.VoiceProfile{
+	"openai": {
+		{ID: "openai-alloy", Name: "Alloy", Provider: "openai", VoiceID: "alloy", Description: "neutral"},
+		{ID: "openai-echo", Name: "Echo", Provider: "openai", VoiceID: "echo", Description: "male"},
+		{ID: "openai-fable", Name: "Fable", Provider: "openai", VoiceID: "fable", Description: "British"},
+		{ID: "openai-onyx", Name: "Onyx", Provider: "openai", VoiceID: "onyx", Description: "deep male"},
+		{ID: "openai-nova", Name: "Nova", Provider: "openai", VoiceID: "nova", Description: "female"},
+		{ID: "openai-shimmer", Name: "Shimmer", Provider: "openai", VoiceID: "shimmer", Description: "soft female"},
+	},
+}
+
+// Builtin returns the voices declared for provider.
+func Builtin(provider string) []models.VoiceProfile {
+	return builtinVoices[provider]
+}
+
+// Configured returns the user-defined voice profiles from config.
+func Configured() []models.VoiceProfile {
+	var profiles []models.VoiceProfile
+	for _, vc := range config.GetVoiceProfiles() {
+		if strings.TrimSpace(vc.Provider) == "" || strings.TrimSpace(vc.VoiceID) == "" {
+			continue
+		}
+		id := vc.ID
+		if id == "" {
+			id = vc.Provider + "-" + vc.VoiceID
+		}
+		name := vc.Name
+		if name == "" {
+			name = vc.VoiceID
+		}
+		profiles = append(profiles, models.VoiceProfile{
+			ID:           id,
+			Name:         name,
+			Provider:     vc.Provider,
+			VoiceID:      vc.VoiceID,
+			Speed:        vc.Speed,
+			Instructions: vc.Instructions,
+			Description:  vc.Description,
+		})
+	}
+	return profiles
+}
+
+// Sync stores built-in voices and user-defined profiles in the database.
+// User profiles are written last so they override built-ins with the same ID.
+func Sync(database *db.DB) {
+	var profiles []models.VoiceProfile
+	for _, provider := range sortedProviders(builtinVoices) {
+		profiles = append(profiles, builtinVoices[provider]...)
+	}
+	profiles = append(profiles, Configured()...)
+
+	for _, p := range profiles {
+		if err := database.CreateVoice(p); err != nil {
+			fmt.Printf("Warning: failed to store voice %s: %v\n", p.ID, err)
+		}
+	}
+}
+
+// Refresh fetches voices from every provider with a voices_url and stores them.
+// It returns the first fetch error but keeps going for other providers.
+func Refresh(ctx context.Context, database *db.DB) error {
+	cfg := config.Get()
+	if cfg == nil {
+		return nil
+	}
+
+	var firstErr error
+	for _, provider := range sortedProviders(cfg.Providers) {
+		if strings.TrimSpace(cfg.Providers[provider].VoicesURL) == "" {
+			continue
+		}
+		fetched, err := Fetch(ctx, provider)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for _, p := range fetched {
+			if exists, err := database.VoiceExists(p.ID); err == nil && exists {
+				// Keep any speed/instructions the user attached to this voice.
+				continue
+			}
+			if err := database.CreateVoice(p); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+type voicesResponse struct {
+	Voices json.RawMessage `json:"voices"`
+}
+
+type remoteVoice struct {
+	ID          string `json:"id"`
+	VoiceID     string `json:"voice_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Fetch lists the voices published at the provider's voices_url.
+// It accepts either a list of voice ids ({"voices": ["af_bella", ...]}) or a
+// list of objects with voice_id/id, name and description.
+func Fetch(ctx context.Context, provider string) ([]models.VoiceProfile, error) {
+	url, err := config.GetProviderVoicesURL(provider)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(url) == "" {
+		return nil, fmt.Errorf("voices url not configured for provider %s", provider)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey, err := config.GetProviderAPIKey(provider); err == nil {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch voices: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return nil, fmt.Errorf("fetch voices failed: %s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+
+	var out voicesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode voices response: %w", err)
+	}
+
+	var remote []remoteVoice
+	var ids []string
+	if err := json.Unmarshal(out.Voices, &ids); err == nil {
+		for _, id := range ids {
+			remote = append(remote, remoteVoice{VoiceID: id})
+		}
+	} else if err := json.Unmarshal(out.Voices, &remote); err != nil {
+		return nil, fmt.Errorf("decode voices response: %w", err)
+	}
+
+	var profiles []models.VoiceProfile
+	for _, rv := range remote {
+		voiceID := rv.VoiceID
+		if voiceID == "" {
+			voiceID = rv.ID
+		}
+		if voiceID == "" {
+			continue
+		}
+		name := rv.Name
+		if name == "" {
+			name = voiceID
+		}
+		profiles = append(profiles, models.VoiceProfile{
+			ID:          provider + "-" + voiceID,
+			Name:        name,
+			Provider:    provider,
+			VoiceID:     voiceID,
+			Description: rv.Description,
+		})
+	}
+	return profiles, nil
+}
+
+// Available returns the stored profiles whose provider has a TTS endpoint.
+func Available(database *db.DB) ([]models.VoiceProfile, error) {
+	rows, err := database.GetAllVoices()
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []models.VoiceProfile
+	for _, v := range rows {
+		if url, err := config.GetProviderTTSURL(v.Provider); err != nil || strings.TrimSpace(url) == "" {
+			continue
+		}
+		profiles = append(profiles, v.Profile())
+	}
+	return profiles, nil
+}
+
+// ForConversation returns the voice profile a conversation was recorded with.
+// Conversations without a stored profile fall back to the legacy voice columns.
+func ForConversation(database *db.DB, c db.Conversation) models.VoiceProfile {
+	if c.VoiceProfileID != "" {
+		if v, err := database.GetVoice(c.VoiceProfileID); err == nil {
+			return v.Profile()
+		}
+	}
+	return models.VoiceProfile{
+		ID:      c.VoiceProfileID,
+		Name:    c.VoiceName,
+		VoiceID: c.VoiceID,
+	}
+}
+
+// HostVoiceNone is stored for conversations whose host lines are not voiced.
+const HostVoiceNone = "none"
+
+// DefaultHost returns the ai.host_voice profile. ok is false when no host
+// voice is configured or the profile doesn't exist.
+func DefaultHost(database *db.DB) (models.VoiceProfile, bool) {
+	return lookupHost(database, config.GetHostVoice())
+}
+
+// HostForConversation returns the voice for a conversation's host lines.
+// ok is false when host lines are not voiced.
+func HostForConversation(database *db.DB, c db.Conversation) (models.VoiceProfile, bool) {
+	switch c.HostVoiceProfileID {
+	case "":
+		return DefaultHost(database)
+	case HostVoiceNone:
+		return models.VoiceProfile{}, false
+	}
+	if v, ok := lookupHost(database, c.HostVoiceProfileID); ok {
+		return v, true
+	}
+	return DefaultHost(database)
+}
+
+func lookupHost(database *db.DB, id string) (models.VoiceProfile, bool) {
+	if id == "" || id == HostVoiceNone {
+		return models.VoiceProfile{}, false
+	}
+	v, err := database.GetVoice(id)
+	if err != nil {
+		return models.VoiceProfile{}, false
+	}
+	return v.Profile(), true
+}
+
+func sortedProviders[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
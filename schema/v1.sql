@@ -0,0 +1,38 @@
+-- VibeCast Database Schema (v1)
+-- Voice profiles, referenced by conversations instead of bare voice ids
+
+-- Voices table: Stores built-in, fetched and user-defined voice profiles
+CREATE TABLE IF NOT EXISTS voices (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    voice_id TEXT NOT NULL,
+    speed REAL NOT NULL DEFAULT 0,
+    instructions TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Trigger to automatically update the updated_at timestamp for voices
+CREATE TRIGGER IF NOT EXISTS update_voices_timestamp
+AFTER UPDATE ON voices
+BEGIN
+    UPDATE voices SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE INDEX IF NOT EXISTS idx_voices_provider ON voices(provider);
+
+-- Conversations reference a voice profile; voice_id/voice_name are kept
+-- as a denormalized fallback for older databases
+ALTER TABLE conversations ADD COLUMN voice_profile_id TEXT REFERENCES voices(id) ON DELETE SET NULL;
+
+-- Backfill profiles for existing conversations (previous voices were OpenAI ids)
+INSERT OR IGNORE INTO voices (id, name, provider, voice_id)
+SELECT DISTINCT 'openai-' || voice_id, voice_name, 'openai', voice_id
+FROM conversations
+WHERE voice_id != '';
+
+UPDATE conversations
+SET voice_profile_id = 'openai-' || voice_id
+WHERE voice_profile_id IS NULL AND voice_id != '';
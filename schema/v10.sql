@@ -0,0 +1,7 @@
+-- VibeCast Database Schema (v10)
+-- Markers for one-off data upgrades, so they don't rerun on every start
+
+CREATE TABLE IF NOT EXISTS meta (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
@@ -0,0 +1,9 @@
+-- VibeCast Database Schema (v11)
+-- Indexed messages keep their take group, so a transcript rebuilt from the
+-- index keeps alternate takes out of the conversation
+
+-- The first take's message id; empty for a first take or a host message
+ALTER TABLE messages ADD COLUMN take_of TEXT NOT NULL DEFAULT '';
+
+-- 1 for a take that isn't the canonical one
+ALTER TABLE messages ADD COLUMN alternate INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- VibeCast Database Schema (v12)
+-- Conversations whose clips retention pruned, in full or in part, so they
+-- aren't rendered with pieces missing
+
+ALTER TABLE conversations ADD COLUMN audio_pruned_at DATETIME;
@@ -0,0 +1,19 @@
+-- VibeCast Database Schema (v2)
+-- Index of synthesized audio clips, so saves don't rescan the audio folder
+
+-- Audio files table: One row per clip under conversations/<id>/audio
+CREATE TABLE IF NOT EXISTS audio_files (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    conversation_id TEXT NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    idx INTEGER NOT NULL,
+    filename TEXT NOT NULL,
+    format TEXT NOT NULL,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    sample_rate INTEGER NOT NULL DEFAULT 0,
+    channels INTEGER NOT NULL DEFAULT 0,
+    size_bytes INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (conversation_id, filename)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audio_files_conversation ON audio_files(conversation_id, idx);
@@ -0,0 +1,6 @@
+-- VibeCast Database Schema (v3)
+-- Record who is speaking in each audio clip, so episodes can be assembled
+-- in transcript order with the right gaps
+
+-- Every clip synthesized before this migration is guest speech
+ALTER TABLE audio_files ADD COLUMN speaker TEXT NOT NULL DEFAULT 'Guest';
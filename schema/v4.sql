@@ -0,0 +1,6 @@
+-- VibeCast Database Schema (v4)
+-- Per-conversation host voice, so typed host lines can be voiced too
+
+-- '' follows ai.host_voice from the config, 'none' leaves host lines silent,
+-- anything else is a voices.id
+ALTER TABLE conversations ADD COLUMN host_voice_profile_id TEXT NOT NULL DEFAULT '';
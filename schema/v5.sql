@@ -0,0 +1,15 @@
+-- VibeCast Database Schema (v5)
+-- Searchable copy of every transcript message. The transcript files stay
+-- the source of truth; rows are written alongside them and backfilled.
+
+-- Messages table: One row per transcript record, keyed by its id
+CREATE TABLE IF NOT EXISTS messages (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message_id TEXT NOT NULL UNIQUE,
+    conversation_id TEXT NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    speaker TEXT NOT NULL,
+    content TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages(conversation_id, created_at);
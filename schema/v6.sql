@@ -0,0 +1,9 @@
+-- VibeCast Database Schema (v6)
+-- Link each audio clip to the transcript message it voices, so earlier
+-- answers can be replayed after resuming a conversation
+
+-- '' until the clip is linked; older clips are linked when their
+-- conversation is next opened
+ALTER TABLE audio_files ADD COLUMN message_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_audio_files_message ON audio_files(conversation_id, message_id);
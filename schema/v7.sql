@@ -0,0 +1,5 @@
+-- VibeCast Database Schema (v7)
+-- Archived conversations are hidden from the list until shown again
+
+-- NULL while the conversation is active
+ALTER TABLE conversations ADD COLUMN archived_at DATETIME;
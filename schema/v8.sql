@@ -0,0 +1,8 @@
+-- VibeCast Database Schema (v8)
+-- Forks remember the conversation and message they were copied from
+
+-- NULL unless the conversation was forked; cleared if the parent is deleted
+ALTER TABLE conversations ADD COLUMN parent_id TEXT REFERENCES conversations(id) ON DELETE SET NULL;
+
+-- The last message copied from the parent
+ALTER TABLE conversations ADD COLUMN forked_from_message_id TEXT NOT NULL DEFAULT '';
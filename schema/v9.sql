@@ -0,0 +1,5 @@
+-- VibeCast Database Schema (v9)
+-- Starred conversations are kept whole by audio retention
+
+-- NULL unless the conversation is starred
+ALTER TABLE conversations ADD COLUMN starred_at DATETIME;